@@ -0,0 +1,59 @@
+package now
+
+import (
+	"sync"
+	"time"
+)
+
+// AliasChange records an alias being moved from one deployment to another.
+// OldAliasUID is the Alias.OldUID returned by the API, kept for reference
+// only: the API cannot look an alias up by it, so Rollback relies on
+// PreviousDeploymentID.
+type AliasChange struct {
+	Alias                string
+	AliasUID             string
+	OldAliasUID          string
+	DeploymentID         string
+	PreviousDeploymentID string
+	Changed              time.Time
+}
+
+// aliasHistory keeps the changes made through Promote, most recent last
+type aliasHistory struct {
+	mu      sync.Mutex
+	changes map[string][]AliasChange
+}
+
+func newAliasHistory() *aliasHistory {
+	return &aliasHistory{changes: make(map[string][]AliasChange)}
+}
+
+func (h *aliasHistory) push(c AliasChange) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.changes[c.Alias] = append(h.changes[c.Alias], c)
+}
+
+func (h *aliasHistory) last(alias string) (AliasChange, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	changes := h.changes[alias]
+	if len(changes) == 0 {
+		return AliasChange{}, false
+	}
+	return changes[len(changes)-1], true
+}
+
+func (h *aliasHistory) pop(alias string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if changes := h.changes[alias]; len(changes) > 0 {
+		h.changes[alias] = changes[:len(changes)-1]
+	}
+}
+
+func (h *aliasHistory) list(alias string) []AliasChange {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]AliasChange{}, h.changes[alias]...)
+}
@@ -0,0 +1,103 @@
+package now
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const defaultPollInterval = 2 * time.Second
+
+// HealthCheck probes a READY deployment and returns an error when it is not
+// fit to receive traffic
+type HealthCheck func(ctx context.Context, d Deployment) error
+
+// HTTPHealthCheck returns a HealthCheck which requests path on the
+// deployment's host and expects the given status code
+func HTTPHealthCheck(h *http.Client, path string, status int) HealthCheck {
+	return func(ctx context.Context, d Deployment) error {
+		req, err := http.NewRequest("GET", "https://"+d.Host+path, nil)
+		if err != nil {
+			return err
+		}
+		res, err := h.Do(req.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+		res.Body.Close()
+		if res.StatusCode != status {
+			return fmt.Errorf("health check on %s%s returned %d, expected %d", d.Host, path, res.StatusCode, status)
+		}
+		return nil
+	}
+}
+
+// BlueGreenOptions contains the optional settings of a blue/green deploy
+type BlueGreenOptions struct {
+	// Params are used to create the new deployment
+	Params DeploymentParams
+	// PollInterval is the delay between state checks while waiting for the
+	// new deployment to become READY
+	PollInterval time.Duration
+	// DeleteOld deletes the previously aliased deployment after the switch
+	DeleteOld bool
+	// ScaleDownOld scales the previously aliased deployment to zero after
+	// the switch. Ignored when DeleteOld is set.
+	ScaleDownOld bool
+}
+
+// BlueGreenResult describes the outcome of a blue/green deploy
+type BlueGreenResult struct {
+	Deployment Deployment
+	Previous   *Deployment
+}
+
+// BlueGreen deploys dir, waits for the new deployment to be READY, runs check
+// against it and only then points alias at it. The alias is left untouched
+// if any step fails; the new deployment is returned in the result so it can
+// be inspected or removed.
+func (c DeploymentsClient) BlueGreen(ctx context.Context, dir, alias string, check HealthCheck, opts BlueGreenOptions) (BlueGreenResult, ClientError) {
+	res := BlueGreenResult{}
+	interval := opts.PollInterval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+
+	inc, err := c.Deploy(dir, opts.Params)
+	if err != nil {
+		return res, err
+	}
+	res.Deployment.UID = inc.ID
+
+	d, err := c.WaitForState(ctx, inc.ID, StateReady, interval)
+	if d.UID != "" {
+		res.Deployment = d
+	}
+	if err != nil {
+		return res, err
+	}
+
+	if check != nil {
+		if hErr := check(ctx, d); hErr != nil {
+			return res, NewError(hErr.Error())
+		}
+	}
+
+	prev, err := c.Promote(alias, d.UID)
+	if err != nil {
+		return res, err
+	}
+	res.Previous = prev
+	if prev == nil || prev.UID == d.UID {
+		return res, nil
+	}
+
+	switch {
+	case opts.DeleteOld:
+		err = c.Delete(prev.UID)
+	case opts.ScaleDownOld:
+		_, err = c.Scale(prev.UID, 0, 0)
+	}
+	return res, err
+}
@@ -0,0 +1,100 @@
+// Package cassette provides an http.RoundTripper which records the
+// interactions of a now.Client with the API to a file and replays them in
+// tests, so they run deterministically without network access or
+// credentials.
+//
+//	r, err := cassette.New("testdata/deploy.json", cassette.ModeReplay, nil)
+//	n := now.New("token")
+//	n.SetHTTPClient(&http.Client{Transport: r})
+//	...
+//	err = r.Stop()
+//
+// Recorded requests never include the Authorization header, and the bearer
+// token, any extra Secrets and the values of ScrubKeys are replaced in query
+// strings and in request and response bodies before they are written. When
+// replaying, the redacted parts of a recorded request match any value.
+package cassette
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"time"
+	"unicode/utf8"
+)
+
+// Redacted replaces scrubbed secrets in recorded interactions
+const Redacted = "[REDACTED]"
+
+// EncodingBase64 marks a body stored base64 encoded, as done for bodies which
+// are not valid UTF-8 and would be altered by a JSON string
+const EncodingBase64 = "base64"
+
+// Interaction is a recorded request and the response it received
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is the recorded part of an http.Request used for matching
+type Request struct {
+	Method   string              `json:"method"`
+	Path     string              `json:"path"`
+	Query    map[string][]string `json:"query,omitempty"`
+	Body     string              `json:"body,omitempty"`
+	Encoding string              `json:"encoding,omitempty"`
+}
+
+// Response is a recorded http.Response
+type Response struct {
+	StatusCode int                 `json:"status"`
+	Header     map[string][]string `json:"header,omitempty"`
+	Body       string              `json:"body,omitempty"`
+	Encoding   string              `json:"encoding,omitempty"`
+}
+
+// Cassette is the file format holding recorded interactions
+type Cassette struct {
+	Recorded     time.Time     `json:"recorded"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Load reads a cassette from path
+func Load(path string) (*Cassette, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c := &Cassette{}
+	if err := json.Unmarshal(b, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Save writes the cassette to path
+func (c *Cassette) Save(path string) error {
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(b, '\n'), os.FileMode(0644))
+}
+
+// encodeBody returns body as stored in a cassette and its encoding
+func encodeBody(body string) (string, string) {
+	if utf8.ValidString(body) {
+		return body, ""
+	}
+	return base64.StdEncoding.EncodeToString([]byte(body)), EncodingBase64
+}
+
+// decodeBody reverses encodeBody
+func decodeBody(body, encoding string) (string, error) {
+	if encoding != EncodingBase64 {
+		return body, nil
+	}
+	b, err := base64.StdEncoding.DecodeString(body)
+	return string(b), err
+}
@@ -0,0 +1,355 @@
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Mode selects whether a Recorder records or replays
+type Mode int
+
+// Modes
+const (
+	// ModeReplay serves responses from the cassette and never touches the
+	// network
+	ModeReplay Mode = iota
+	// ModeRecord performs real requests and saves them on Stop
+	ModeRecord
+)
+
+// DefaultScrubKeys are the JSON keys and query parameters whose values are
+// redacted by default
+var DefaultScrubKeys = []string{"token", "bearerToken", "key", "password", "value", "secret"}
+
+// Recorder is an http.RoundTripper recording to or replaying from a cassette
+type Recorder struct {
+	// Secrets lists extra strings redacted from recorded bodies
+	Secrets []string
+	// ScrubKeys lists the JSON keys whose values are redacted from
+	// recorded bodies, at any depth, and the redacted query parameters
+	ScrubKeys []string
+
+	mode      Mode
+	path      string
+	transport http.RoundTripper
+
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+}
+
+// New returns a Recorder for the cassette at path. In ModeReplay the
+// cassette must exist; in ModeRecord requests are sent through transport,
+// or http.DefaultTransport when nil.
+func New(path string, mode Mode, transport http.RoundTripper) (*Recorder, error) {
+	r := &Recorder{
+		ScrubKeys: append([]string{}, DefaultScrubKeys...),
+		mode:      mode,
+		path:      path,
+		transport: transport,
+		cassette:  &Cassette{},
+	}
+	if r.transport == nil {
+		r.transport = http.DefaultTransport
+	}
+	if mode == ModeReplay {
+		c, err := Load(path)
+		if err != nil {
+			return nil, err
+		}
+		r.cassette = c
+		r.used = make([]bool, len(c.Interactions))
+	}
+	return r, nil
+}
+
+// RoundTrip implements the http.RoundTripper interface
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	live := Request{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Query:  req.URL.Query(),
+		Body:   body,
+	}
+	if len(live.Query) == 0 {
+		live.Query = nil
+	}
+
+	if r.mode == ModeReplay {
+		return r.replay(req, live)
+	}
+	return r.record(req, r.scrubRequest(live, bearer(req)))
+}
+
+// Stop saves the cassette when recording. It is a no-op when replaying.
+func (r *Recorder) Stop() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Recorded = time.Now().UTC()
+	return r.cassette.Save(r.path)
+}
+
+func (r *Recorder) replay(req *http.Request, live Request) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, in := range r.cassette.Interactions {
+		if r.used[i] || !matches(in.Request, live) {
+			continue
+		}
+		body, err := decodeBody(in.Response.Body, in.Response.Encoding)
+		if err != nil {
+			return nil, err
+		}
+		r.used[i] = true
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", in.Response.StatusCode, http.StatusText(in.Response.StatusCode)),
+			StatusCode:    in.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header(in.Response.Header),
+			Body:          ioutil.NopCloser(strings.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("cassette: no recorded interaction for %s %s", live.Method, req.URL.RequestURI())
+}
+
+func (r *Recorder) record(req *http.Request, recorded Request) (*http.Response, error) {
+	res, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	b, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(b))
+
+	header := http.Header{}
+	for k, v := range res.Header {
+		// Bodies may change length when scrubbed
+		if k != "Set-Cookie" && k != "Content-Length" {
+			header[k] = v
+		}
+	}
+
+	recordedRes := Response{
+		StatusCode: res.StatusCode,
+		Header:     header,
+	}
+	recordedRes.Body, recordedRes.Encoding = encodeBody(r.scrub(string(b), bearer(req)))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Request:  recorded,
+		Response: recordedRes,
+	})
+	return res, nil
+}
+
+// readBody returns the request body and restores it for the transport
+func readBody(req *http.Request) (string, error) {
+	if req.Body == nil {
+		return "", nil
+	}
+	b, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return "", err
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(b))
+	return string(b), nil
+}
+
+func bearer(req *http.Request) string {
+	return strings.TrimSpace(strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer"))
+}
+
+// scrubRequest redacts the secrets from the query and body of a request and
+// encodes its body for the cassette
+func (r *Recorder) scrubRequest(req Request, token string) Request {
+	if req.Query != nil {
+		query := make(map[string][]string, len(req.Query))
+		for k, values := range req.Query {
+			scrubbed := make([]string, len(values))
+			for i, v := range values {
+				if containsKey(r.ScrubKeys, k) {
+					v = Redacted
+				}
+				scrubbed[i] = r.replaceSecrets(v, token)
+			}
+			query[k] = scrubbed
+		}
+		req.Query = query
+	}
+	req.Body, req.Encoding = encodeBody(r.scrub(req.Body, token))
+	return req
+}
+
+func (r *Recorder) replaceSecrets(s, token string) string {
+	for _, secret := range append([]string{token}, r.Secrets...) {
+		if secret != "" {
+			s = strings.Replace(s, secret, Redacted, -1)
+		}
+	}
+	return s
+}
+
+// scrub redacts the token, the extra secrets and the values of ScrubKeys
+func (r *Recorder) scrub(body, token string) string {
+	if body == "" {
+		return body
+	}
+	body = r.replaceSecrets(body, token)
+	if len(r.ScrubKeys) == 0 {
+		return body
+	}
+
+	var v interface{}
+	if err := json.Unmarshal([]byte(body), &v); err != nil {
+		return body
+	}
+	if !scrubKeys(v, r.ScrubKeys) {
+		return body
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return body
+	}
+	return string(b)
+}
+
+// scrubKeys redacts the string values of keys in v, returning whether any
+// value was changed
+func scrubKeys(v interface{}, keys []string) bool {
+	changed := false
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, val := range t {
+			if _, ok := val.(string); ok && containsKey(keys, k) {
+				t[k] = Redacted
+				changed = true
+				continue
+			}
+			changed = scrubKeys(val, keys) || changed
+		}
+	case []interface{}:
+		for _, val := range t {
+			changed = scrubKeys(val, keys) || changed
+		}
+	}
+	return changed
+}
+
+func containsKey(keys []string, k string) bool {
+	for _, key := range keys {
+		if key == k {
+			return true
+		}
+	}
+	return false
+}
+
+// matches compares a recorded request with a live one by method, path,
+// query and body, JSON bodies being compared by value. Redacted parts of the
+// recorded request match anything.
+func matches(recorded, live Request) bool {
+	if recorded.Method != live.Method || recorded.Path != live.Path {
+		return false
+	}
+	if len(recorded.Query) != len(live.Query) {
+		return false
+	}
+	for k, values := range recorded.Query {
+		if len(values) != len(live.Query[k]) {
+			return false
+		}
+		for i, v := range values {
+			if !redactedMatch(v, live.Query[k][i]) {
+				return false
+			}
+		}
+	}
+
+	body, err := decodeBody(recorded.Body, recorded.Encoding)
+	if err != nil {
+		return false
+	}
+	if redactedMatch(body, live.Body) {
+		return true
+	}
+	var rv, lv interface{}
+	if json.Unmarshal([]byte(body), &rv) != nil || json.Unmarshal([]byte(live.Body), &lv) != nil {
+		return false
+	}
+	return jsonMatch(rv, lv)
+}
+
+// jsonMatch compares decoded JSON values, recorded strings matching as in
+// redactedMatch
+func jsonMatch(recorded, live interface{}) bool {
+	switch r := recorded.(type) {
+	case string:
+		l, ok := live.(string)
+		return ok && redactedMatch(r, l)
+	case map[string]interface{}:
+		l, ok := live.(map[string]interface{})
+		if !ok || len(l) != len(r) {
+			return false
+		}
+		for k, v := range r {
+			if lv, ok := l[k]; !ok || !jsonMatch(v, lv) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		l, ok := live.([]interface{})
+		if !ok || len(l) != len(r) {
+			return false
+		}
+		for i := range r {
+			if !jsonMatch(r[i], l[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(recorded, live)
+	}
+}
+
+// redactedMatch returns whether live equals recorded, each Redacted in
+// recorded standing for any text
+func redactedMatch(recorded, live string) bool {
+	if recorded == live {
+		return true
+	}
+	if !strings.Contains(recorded, Redacted) {
+		return false
+	}
+	parts := strings.Split(recorded, Redacted)
+	for i, p := range parts {
+		parts[i] = regexp.QuoteMeta(p)
+	}
+	return regexp.MustCompile("^(?s:" + strings.Join(parts, ".*") + ")$").MatchString(live)
+}
@@ -0,0 +1,153 @@
+package cassette
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	now "github.com/manifoldco/go-now"
+)
+
+const token = "secret-token"
+
+var binary = string([]byte{0xff, 0xfe, 0x00, 'a', 0x80})
+
+// rewrite sends every request to the test server
+type rewrite struct {
+	target *url.URL
+}
+
+func (rw rewrite) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.URL.Scheme = rw.target.Scheme
+	req.URL.Host = rw.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestRecordReplay(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/domains":
+			if r.URL.Query().Get("teamId") != "team_x" {
+				http.Error(w, "missing teamId", http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"domains":[{"uid":"d1","name":"example.com","verifyToken":"` + token + `"}]}`))
+		case "/now/secrets":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"uid":"sec_1","name":"api-token"}`))
+		case "/now/registration/verify":
+			w.Write([]byte(`{"token":"issued-token"}`))
+		case "/binary":
+			w.Write([]byte(binary))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	target, _ := url.Parse(srv.URL)
+	dir, err := ioutil.TempDir("", "cassette")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "cassette.json")
+
+	rec, err := New(path, ModeRecord, rewrite{target})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := now.New(token)
+	n.SetHTTPClient(&http.Client{Transport: rec})
+	n.SetTeamID("team_x")
+	if _, err := n.Domains.List(); err != nil {
+		t.Fatalf("recording domains: %v", err)
+	}
+	if _, err := n.Secrets.New("api-token", "secret-value"); err != nil {
+		t.Fatalf("recording secret: %v", err)
+	}
+	get(t, rec, "/now/registration/verify?email=a%40b.c&token=verify-token")
+	if got := get(t, rec, "/binary"); got != binary {
+		t.Fatalf("recorded binary body %q, want %q", got, binary)
+	}
+	if err := rec.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	srv.Close()
+
+	saved, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{token, "secret-value", "verify-token", "issued-token"} {
+		if strings.Contains(string(saved), secret) {
+			t.Fatalf("%s not scrubbed from cassette:\n%s", secret, saved)
+		}
+	}
+
+	rep, err := New(path, ModeReplay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A token which appears in request bodies must not affect matching
+	n = now.New("token")
+	n.SetHTTPClient(&http.Client{Transport: rep})
+
+	if _, err := n.Domains.List(); err == nil {
+		t.Fatal("replayed a request without teamId")
+	}
+	n.SetTeamID("team_x")
+	domains, cErr := n.Domains.List()
+	if cErr != nil {
+		t.Fatalf("replaying domains: %v", cErr)
+	}
+	if len(domains) != 1 || domains[0].Name != "example.com" || domains[0].VerifyToken != Redacted {
+		t.Fatalf("unexpected replayed domains %+v", domains)
+	}
+
+	if _, err := n.Secrets.New("api-token", "other-value"); err != nil {
+		t.Fatalf("replaying secret: %v", err)
+	}
+	if _, err := do(rep, "GET", "/now/registration/verify?email=a%40b.c&token=other-token"); err != nil {
+		t.Fatalf("replaying a request with a scrubbed query: %v", err)
+	}
+
+	if _, err := do(rep, "POST", "/binary"); err == nil {
+		t.Fatal("replayed a request with another method")
+	}
+	if _, err := do(rep, "GET", "/binary?x=1"); err == nil {
+		t.Fatal("replayed a request with another query")
+	}
+	if _, err := do(rep, "GET", "/other"); err == nil {
+		t.Fatal("replayed a request with another path")
+	}
+	if got := get(t, rep, "/binary"); got != binary {
+		t.Fatalf("replayed binary body %q, want %q", got, binary)
+	}
+}
+
+func do(rt http.RoundTripper, method, path string) (string, error) {
+	req, err := http.NewRequest(method, "http://api.example.test"+path, nil)
+	if err != nil {
+		return "", err
+	}
+	res, err := (&http.Client{Transport: rt}).Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	b, err := ioutil.ReadAll(res.Body)
+	return string(b), err
+}
+
+func get(t *testing.T, rt http.RoundTripper, path string) string {
+	body, err := do(rt, "GET", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
@@ -4,6 +4,10 @@ import "time"
 
 // Cert is the contents of an ssl certificate object
 type Cert struct {
-	UID     string     `json:"uid"`
-	Created *time.Time `json:"created,omitempty"`
+	UID         string     `json:"uid"`
+	CommonNames []string   `json:"cns,omitempty"`
+	Issuer      string     `json:"issuer,omitempty"`
+	AutoRenew   bool       `json:"autoRenew"`
+	Created     *time.Time `json:"created,omitempty"`
+	Expiration  *time.Time `json:"expiration,omitempty"`
 }
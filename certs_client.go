@@ -43,6 +43,33 @@ type CertParams struct {
 	Renew       bool     `json:"renew"`
 }
 
+// Upload adds a custom certificate from PEM encoded data. caPEM holds the
+// intermediate chain and may be empty.
+func (c CertsClient) Upload(certPEM, keyPEM, caPEM string) (Cert, ClientError) {
+	crt := Cert{}
+	params := CertUploadParams{
+		Cert: certPEM,
+		Key:  keyPEM,
+		CA:   caPEM,
+	}
+	err := c.client.NewRequest("PUT", certsEndpoint, params, &crt, nil)
+	return crt, err
+}
+
+// CertUploadParams contains all fields for a custom certificate upload
+type CertUploadParams struct {
+	Cert string `json:"cert"`
+	Key  string `json:"key"`
+	CA   string `json:"ca,omitempty"`
+}
+
+// Get retrieves a cert by its ID or one of its domain names
+func (c CertsClient) Get(idOrDomain string) (Cert, ClientError) {
+	crt := Cert{}
+	err := c.client.NewRequest("GET", fmt.Sprintf("%s/%s", certsEndpoint, idOrDomain), nil, &crt, nil)
+	return crt, err
+}
+
 // List retrieves a list of all the domains under the account
 func (c CertsClient) List() ([]*Cert, ClientError) {
 	crt := &certListResponse{}
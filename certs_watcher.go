@@ -0,0 +1,156 @@
+package now
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+const (
+	defaultCertWatchInterval = 12 * time.Hour
+	defaultCertRenewWithin   = 14 * 24 * time.Hour
+
+	// managedCertIssuer prefixes the issuer of the certs Now provisions
+	managedCertIssuer = "Let's Encrypt"
+)
+
+// CertEventType represents a CertEvent type string
+type CertEventType string
+
+// CertEventTypes
+const (
+	CertExpiring     CertEventType = "expiring"
+	CertRenewed      CertEventType = "renewed"
+	CertRenewFailed  CertEventType = "failed"
+	CertWatchFailure CertEventType = "watch_failed"
+)
+
+// CertEvent is emitted by a CertWatcher. Cert is the certificate inspected,
+// Renewed the replacement on a successful renewal and Err the cause of a
+// failure.
+type CertEvent struct {
+	Type    CertEventType
+	Cert    *Cert
+	Renewed *Cert
+	Err     ClientError
+}
+
+// CertWatcher periodically inspects the account's certificates, renews those
+// expiring within RenewWithin and reports those expiring within WarnWithin.
+// Certs replaced by a later one for the same names are ignored, and uploaded
+// or auto renewed certs are only reported.
+type CertWatcher struct {
+	certs *CertsClient
+
+	// Interval is the delay between two inspections, the default one is used
+	// when it is not positive
+	Interval time.Duration
+	// RenewWithin is how close to its expiration a cert is renewed
+	RenewWithin time.Duration
+	// WarnWithin is how close to its expiration a cert that is not renewed
+	// emits an expiring event
+	WarnWithin time.Duration
+	// OnEvent receives every event, it must not block for long
+	OnEvent func(CertEvent)
+}
+
+// NewCertWatcher returns a CertWatcher using the default interval and
+// renewal window which sends its events to onEvent
+func NewCertWatcher(certs *CertsClient, onEvent func(CertEvent)) *CertWatcher {
+	return &CertWatcher{
+		certs:       certs,
+		Interval:    defaultCertWatchInterval,
+		RenewWithin: defaultCertRenewWithin,
+		WarnWithin:  2 * defaultCertRenewWithin,
+		OnEvent:     onEvent,
+	}
+}
+
+// Run inspects the certificates every Interval until ctx is done
+func (w *CertWatcher) Run(ctx context.Context) {
+	interval := w.Interval
+	if interval <= 0 {
+		interval = defaultCertWatchInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := w.Check(); err != nil {
+			w.emit(CertEvent{Type: CertWatchFailure, Err: err})
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check performs a single inspection of the certificates. Only a failure to
+// list them is returned; renewal failures are reported as events.
+func (w *CertWatcher) Check() ClientError {
+	certs, err := w.certs.List()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, crt := range certs {
+		if crt.Expiration == nil || superseded(crt, certs) {
+			continue
+		}
+		left := crt.Expiration.Sub(now)
+		switch {
+		case left <= w.RenewWithin && renewable(crt):
+			renewed, err := w.certs.Renew(crt.CommonNames)
+			if err != nil {
+				w.emit(CertEvent{Type: CertRenewFailed, Cert: crt, Err: err})
+				continue
+			}
+			w.emit(CertEvent{Type: CertRenewed, Cert: crt, Renewed: &renewed})
+		case left <= w.WarnWithin:
+			w.emit(CertEvent{Type: CertExpiring, Cert: crt})
+		}
+	}
+	return nil
+}
+
+// renewable returns whether crt was issued by Now and is not already renewed
+// automatically
+func renewable(crt *Cert) bool {
+	managed := crt.Issuer == "" || strings.HasPrefix(crt.Issuer, managedCertIssuer)
+	return managed && !crt.AutoRenew
+}
+
+// superseded returns whether another of certs covers the names of crt and
+// expires after it
+func superseded(crt *Cert, certs []*Cert) bool {
+	for _, other := range certs {
+		if other == crt || other.Expiration == nil || !other.Expiration.After(*crt.Expiration) {
+			continue
+		}
+		if coversNames(other.CommonNames, crt.CommonNames) {
+			return true
+		}
+	}
+	return false
+}
+
+func coversNames(cns, names []string) bool {
+	have := make(map[string]bool, len(cns))
+	for _, cn := range cns {
+		have[cn] = true
+	}
+	for _, n := range names {
+		if !have[n] {
+			return false
+		}
+	}
+	return len(names) > 0
+}
+
+func (w *CertWatcher) emit(e CertEvent) {
+	if w.OnEvent != nil {
+		w.OnEvent(e)
+	}
+}
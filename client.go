@@ -2,7 +2,9 @@ package now
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"os"
@@ -25,7 +27,7 @@ func (c Client) Authenticated() bool {
 }
 
 // SetHTTPClient overrides the default HTTP client used
-func (c Client) SetHTTPClient(h *http.Client) {
+func (c *Client) SetHTTPClient(h *http.Client) {
 	c.HTTPClient = h
 }
 
@@ -73,6 +75,12 @@ func (c Client) NewFileRequest(method, path string, file *os.File, v interface{}
 
 // NewRequest performs an authenticated request for the given params
 func (c Client) NewRequest(method, path string, body interface{}, v interface{}, headers *map[string]string) ClientError {
+	return c.NewRequestContext(context.Background(), method, path, body, v, headers)
+}
+
+// NewRequestContext performs an authenticated request for the given params,
+// aborting it when ctx is cancelled
+func (c Client) NewRequestContext(ctx context.Context, method, path string, body interface{}, v interface{}, headers *map[string]string) ClientError {
 	if !strings.HasPrefix(path, "/") {
 		path = "/" + path
 	}
@@ -93,10 +101,45 @@ func (c Client) NewRequest(method, path string, body interface{}, v interface{},
 		return NewError(rErr.Error())
 	}
 
-	return c.performRequest(req, headers, v)
+	return c.performRequest(req.WithContext(ctx), headers, v)
 }
 
-func (c Client) performRequest(req *http.Request, headers *map[string]string, v interface{}) ClientError {
+// NewStreamRequest performs an authenticated request and returns the response
+// body unread so the caller can consume it as it arrives. The caller must
+// close the body. Cancelling ctx aborts the request.
+func (c Client) NewStreamRequest(ctx context.Context, method, path string, headers *map[string]string) (io.ReadCloser, ClientError) {
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	path = c.URL + path
+
+	req, err := http.NewRequest(method, path, nil)
+	if err != nil {
+		return nil, NewError(err.Error())
+	}
+	c.prepareRequest(req, headers)
+
+	// Streams may stay open far longer than the client's timeout allows
+	h := *c.HTTPClient
+	h.Timeout = 0
+	res, err := h.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, NewError(err.Error())
+	}
+	switch res.StatusCode {
+	case 200, 202, 201, 204:
+		return res.Body, nil
+	default:
+		defer res.Body.Close()
+		resBody, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			return nil, NewError(err.Error())
+		}
+		return nil, responseError(res.StatusCode, resBody)
+	}
+}
+
+func (c Client) prepareRequest(req *http.Request, headers *map[string]string) {
 	req.Header.Set("Content-Type", "application/json")
 	if headers != nil {
 		for k, v := range *headers {
@@ -112,6 +155,10 @@ func (c Client) performRequest(req *http.Request, headers *map[string]string, v
 		q.Add("teamId", c.teamID)
 		req.URL.RawQuery = q.Encode()
 	}
+}
+
+func (c Client) performRequest(req *http.Request, headers *map[string]string, v interface{}) ClientError {
+	c.prepareRequest(req, headers)
 
 	// Perform the request
 	res, err := c.HTTPClient.Do(req)
@@ -137,13 +184,17 @@ func (c Client) performRequest(req *http.Request, headers *map[string]string, v
 	case 304:
 		return nil
 	default:
-		zeitErrResp := ErrZeitResponse{}
-		if len(resBody) > 0 {
-			marshalErr := json.Unmarshal(resBody, &zeitErrResp)
-			if marshalErr != nil {
-				return NewError("Invalid API response")
-			}
+		return responseError(res.StatusCode, resBody)
+	}
+}
+
+func responseError(statusCode int, resBody []byte) ClientError {
+	zeitErrResp := ErrZeitResponse{}
+	if len(resBody) > 0 {
+		marshalErr := json.Unmarshal(resBody, &zeitErrResp)
+		if marshalErr != nil {
+			return NewError("Invalid API response")
 		}
-		return NewZeitError(res.StatusCode, zeitErrResp.ZeitError())
 	}
+	return NewZeitError(statusCode, zeitErrResp.ZeitError())
 }
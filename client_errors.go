@@ -22,10 +22,16 @@ func (e errResponse) StatusCode() int {
 }
 
 func (e errResponse) Code() string {
+	if e.zeitError == nil {
+		return "unknown_error"
+	}
 	return e.zeitError.Code
 }
 
 func (e errResponse) Message() string {
+	if e.zeitError == nil {
+		return fmt.Sprintf("Request failed with status %d", e.statusCode)
+	}
 	return e.zeitError.Message
 }
 
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/manifoldco/go-now/reconcile"
+)
+
+func applyCmd(a *app, args []string) error {
+	fs := flag.NewFlagSet("apply", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "only print the plan")
+	prune := fs.Bool("prune", false, "delete resources missing from the manifest")
+	fs.SetOutput(ioutil.Discard)
+	if err := fs.Parse(args); err != nil || fs.NArg() != 1 {
+		return usageError{applyUsage}
+	}
+
+	m, err := reconcile.Load(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	plan, err := reconcile.Diff(a.now, m, reconcile.Options{Prune: *prune})
+	if err != nil {
+		return err
+	}
+	if err := plan.Write(os.Stdout); err != nil {
+		return err
+	}
+	if *dryRun || plan.Empty() {
+		return nil
+	}
+
+	applied, err := plan.Apply(a.now)
+	fmt.Printf("Applied %d of %d changes.\n", applied, len(plan.Changes))
+	return err
+}
@@ -0,0 +1,313 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"io/ioutil"
+	"strconv"
+	"time"
+
+	"github.com/manifoldco/go-now"
+	"github.com/manifoldco/go-now/format"
+)
+
+func deployCmd(a *app, args []string) error {
+	fs := flag.NewFlagSet("deploy", flag.ContinueOnError)
+	name := fs.String("name", "", "deployment name")
+	public := fs.Bool("public", false, "make the source public")
+	wait := fs.Bool("wait", false, "wait for the deployment to be READY")
+	env := keyValues{}
+	fs.Var(env, "env", "environment variable as KEY=VALUE, repeatable")
+	meta := keyValues{}
+	fs.Var(meta, "meta", "meta label as KEY=VALUE, repeatable")
+	fs.SetOutput(ioutil.Discard)
+	if err := fs.Parse(args); err != nil || fs.NArg() > 1 {
+		return usageError{deployUsage}
+	}
+	dir := "."
+	if fs.NArg() == 1 {
+		dir = fs.Arg(0)
+	}
+
+	d, err := a.now.Deployments.Deploy(dir, now.DeploymentParams{
+		Name:   *name,
+		Public: *public,
+		Env:    env,
+		Meta:   meta,
+	})
+	if err != nil {
+		return err
+	}
+	if !*wait {
+		return a.print(d)
+	}
+
+	dep, err := a.now.Deployments.WaitForState(context.Background(), d.ID, now.StateReady, 2*time.Second)
+	if err != nil {
+		return err
+	}
+	return a.print(dep)
+}
+
+func lsCmd(a *app, args []string) error {
+	fs := flag.NewFlagSet("ls", flag.ContinueOnError)
+	meta := keyValues{}
+	fs.Var(meta, "meta", "only list deployments with the meta label KEY=VALUE, repeatable")
+	fs.SetOutput(ioutil.Discard)
+	if err := fs.Parse(args); err != nil || fs.NArg() != 0 {
+		return usageError{lsUsage}
+	}
+	var selectors []now.MetaSelector
+	for k, v := range meta {
+		selectors = append(selectors, now.MetaEquals(k, v))
+	}
+
+	deployments, err := a.now.Deployments.List(selectors...)
+	if err != nil {
+		return err
+	}
+	return a.print(deployments)
+}
+
+func rmCmd(a *app, args []string) error {
+	if len(args) == 0 {
+		return usageError{rmUsage}
+	}
+	for _, id := range args {
+		if err := a.now.Deployments.Delete(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func aliasCmd(a *app, args []string) error {
+	switch {
+	case len(args) >= 1 && args[0] == "ls":
+		var aliases []now.Alias
+		var err now.ClientError
+		switch len(args) {
+		case 1:
+			aliases, err = a.now.Deployments.AllAliases()
+		case 2:
+			aliases, err = a.now.Deployments.ListAliases(args[1])
+		default:
+			return usageError{aliasUsage}
+		}
+		if err != nil {
+			return err
+		}
+		return a.print(aliases)
+	case len(args) == 2:
+		al, err := a.now.Deployments.Alias(args[0], args[1])
+		if err != nil {
+			return err
+		}
+		return a.print(al)
+	default:
+		return usageError{aliasUsage}
+	}
+}
+
+func scaleCmd(a *app, args []string) error {
+	if len(args) == 1 {
+		instances, err := a.now.Deployments.Instances(args[0])
+		if err != nil {
+			return err
+		}
+		return a.print(instances)
+	}
+	if len(args) != 2 && len(args) != 3 {
+		return usageError{scaleUsage}
+	}
+
+	min, err := strconv.Atoi(args[1])
+	if err != nil {
+		return usageError{scaleUsage}
+	}
+	max := min
+	if len(args) == 3 {
+		if max, err = strconv.Atoi(args[2]); err != nil {
+			return usageError{scaleUsage}
+		}
+	}
+	d, cErr := a.now.Deployments.Scale(args[0], min, max)
+	if cErr != nil {
+		return cErr
+	}
+	return a.print(d)
+}
+
+func domainsCmd(a *app, args []string) error {
+	usage := usageError{domainsUsage}
+	if len(args) == 0 {
+		args = []string{"ls"}
+	}
+	switch {
+	case args[0] == "ls" && len(args) == 1:
+		domains, err := a.now.Domains.List()
+		if err != nil {
+			return err
+		}
+		return a.print(domains)
+	case args[0] == "add" && (len(args) == 2 || len(args) == 3):
+		external := len(args) == 3 && args[2] == "--external"
+		if len(args) == 3 && !external {
+			return usage
+		}
+		d, err := a.now.Domains.New(args[1], external)
+		if err != nil {
+			return err
+		}
+		return a.print(d)
+	case args[0] == "rm" && len(args) == 2:
+		return a.now.Domains.Delete(args[1])
+	case args[0] == "verify" && len(args) == 2:
+		d, err := a.now.Domains.Verify(args[1])
+		if err != nil {
+			return err
+		}
+		return a.print(d)
+	case args[0] == "status" && len(args) == 2:
+		s, err := a.now.Domains.Status(args[1])
+		if err != nil {
+			return err
+		}
+		return a.print(s)
+	case args[0] == "price" && len(args) == 2:
+		p, err := a.now.Domains.Price(args[1])
+		if err != nil {
+			return err
+		}
+		return a.print(p)
+	case args[0] == "buy" && len(args) == 3:
+		price, err := strconv.Atoi(args[2])
+		if err != nil {
+			return usage
+		}
+		return a.now.Domains.Buy(args[1], price)
+	default:
+		return usage
+	}
+}
+
+func certsCmd(a *app, args []string) error {
+	usage := usageError{certsUsage}
+	if len(args) == 0 {
+		args = []string{"ls"}
+	}
+	switch {
+	case args[0] == "ls" && len(args) == 1:
+		certs, err := a.now.Certs.List()
+		if err != nil {
+			return err
+		}
+		return a.print(certs)
+	case args[0] == "get" && len(args) == 2:
+		c, err := a.now.Certs.Get(args[1])
+		if err != nil {
+			return err
+		}
+		return a.print(c)
+	case args[0] == "add" && len(args) > 1:
+		c, err := a.now.Certs.New(args[1:])
+		if err != nil {
+			return err
+		}
+		return a.print(c)
+	case args[0] == "renew" && len(args) > 1:
+		c, err := a.now.Certs.Renew(args[1:])
+		if err != nil {
+			return err
+		}
+		return a.print(c)
+	case args[0] == "rm" && len(args) == 2:
+		return a.now.Certs.Delete(args[1])
+	default:
+		return usage
+	}
+}
+
+func teamsCmd(a *app, args []string) error {
+	usage := usageError{teamsUsage}
+	if len(args) == 0 {
+		args = []string{"ls"}
+	}
+	switch {
+	case args[0] == "ls" && len(args) == 1:
+		teams, err := a.now.Teams.List()
+		if err != nil {
+			return err
+		}
+		return a.print(teams)
+	case args[0] == "add" && len(args) == 2:
+		t, err := a.now.Teams.New(args[1])
+		if err != nil {
+			return err
+		}
+		return a.print(t)
+	case args[0] == "rm" && len(args) == 2:
+		return a.now.Teams.Delete(args[1])
+	case args[0] == "members" && len(args) == 2:
+		members, err := a.now.Teams.Members(args[1])
+		if err != nil {
+			return err
+		}
+		return a.print(members)
+	case args[0] == "invite" && len(args) == 3:
+		return a.now.Teams.InviteUser(args[1], args[2])
+	default:
+		return usage
+	}
+}
+
+func planCmd(a *app, args []string) error {
+	usage := usageError{planUsage}
+	if len(args) == 0 {
+		args = []string{"show"}
+	}
+	switch {
+	case args[0] == "show" && len(args) == 1:
+		s, err := a.now.Plans.Current()
+		if err != nil {
+			return err
+		}
+		return a.print(s)
+	case args[0] == "usage" && len(args) == 1:
+		u, err := a.now.Plans.Usage()
+		if err != nil {
+			return err
+		}
+		if a.opts.Format != format.Table {
+			return a.print(u)
+		}
+		return a.print([]usageLine{
+			newUsageLine("bandwidth", u.Bandwidth),
+			newUsageLine("instances", u.Instances),
+			newUsageLine("deployments", u.Deployments),
+		})
+	case args[0] == "set" && len(args) == 3 && args[2] == "--yes":
+		s, err := a.now.Plans.Set(args[1], true)
+		if err != nil {
+			return err
+		}
+		return a.print(s)
+	default:
+		return usage
+	}
+}
+
+// usageLine is a row of the plan usage table
+type usageLine struct {
+	Resource string
+	Used     int64
+	Limit    string
+}
+
+func newUsageLine(name string, m now.UsageMetric) usageLine {
+	limit := "unlimited"
+	if m.Limit > 0 {
+		limit = strconv.FormatInt(m.Limit, 10)
+	}
+	return usageLine{Resource: name, Used: m.Used, Limit: limit}
+}
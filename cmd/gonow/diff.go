@@ -0,0 +1,25 @@
+package main
+
+import (
+	"os"
+
+	"github.com/manifoldco/go-now"
+)
+
+func diffCmd(a *app, args []string) error {
+	if len(args) != 2 {
+		return usageError{diffUsage}
+	}
+
+	var changes []now.FileChange
+	var err now.ClientError
+	if f, sErr := os.Stat(args[1]); sErr == nil && f.IsDir() {
+		changes, err = a.now.Deployments.DiffDir(args[0], args[1])
+	} else {
+		changes, err = a.now.Deployments.Diff(args[0], args[1])
+	}
+	if err != nil {
+		return err
+	}
+	return a.print(changes)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/manifoldco/go-now"
+)
+
+func exportCmd(a *app, args []string) error {
+	if len(args) > 1 {
+		return usageError{exportUsage}
+	}
+	doc, err := a.now.Export()
+	if err != nil {
+		return err
+	}
+
+	out := os.Stdout
+	if len(args) == 1 {
+		f, err := os.Create(args[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+func importCmd(a *app, args []string) error {
+	if len(args) != 1 && len(args) != 2 {
+		return usageError{importUsage}
+	}
+	f, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	doc, err := now.ReadExport(f)
+	if err != nil {
+		return err
+	}
+
+	var team string
+	if len(args) == 2 {
+		team = args[1]
+	}
+	report, cErr := a.now.Import(doc, team)
+	if report != nil {
+		if err := a.print(report.Conflicts); err != nil {
+			return err
+		}
+	}
+	return cErr
+}
@@ -0,0 +1,207 @@
+// Command gonow is a command line client for Zeit Now built on go-now.
+//
+// Usage:
+//
+//	gonow [--token TOKEN] [--team TEAM] [--json | --format FORMAT] COMMAND [ARGS]
+//
+// The token and team default to the values resolved by now.LoadCredentials.
+// API failures exit with a code derived from the HTTP status of the response.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/manifoldco/go-now"
+	"github.com/manifoldco/go-now/format"
+)
+
+// Exit codes
+const (
+	exitOK = iota
+	exitError
+	exitUsage
+	exitUnauthorized
+	exitNotFound
+	exitConflict
+	exitRateLimited
+	exitServerError
+)
+
+type command struct {
+	usage string
+	run   func(a *app, args []string) error
+}
+
+// Command usages
+const (
+	deployUsage  = "deploy [--name NAME] [--public] [--wait] [--env KEY=VALUE]... [--meta KEY=VALUE]... [DIR]"
+	lsUsage      = "ls [--meta KEY=VALUE]..."
+	rmUsage      = "rm ID..."
+	aliasUsage   = "alias ID ALIAS | alias ls [ID]"
+	applyUsage   = "apply [--dry-run] [--prune] MANIFEST"
+	scaleUsage   = "scale ID [MIN [MAX]]"
+	diffUsage    = "diff ID (ID | DIR)"
+	exportUsage  = "export [FILE]"
+	importUsage  = "import FILE [TEAM]"
+	domainsUsage = "domains [ls | add NAME [--external] | rm NAME | verify NAME | status NAME | price NAME | buy NAME PRICE]"
+	certsUsage   = "certs [ls | get ID | add DOMAIN... | renew DOMAIN... | rm DOMAIN]"
+	teamsUsage   = "teams [ls | add SLUG | rm ID | members ID | invite ID EMAIL]"
+	planUsage    = "plan [show | usage | set PLAN --yes]"
+)
+
+var commands = map[string]command{
+	"deploy":  {deployUsage, deployCmd},
+	"ls":      {lsUsage, lsCmd},
+	"rm":      {rmUsage, rmCmd},
+	"alias":   {aliasUsage, aliasCmd},
+	"apply":   {applyUsage, applyCmd},
+	"scale":   {scaleUsage, scaleCmd},
+	"diff":    {diffUsage, diffCmd},
+	"domains": {domainsUsage, domainsCmd},
+	"export":  {exportUsage, exportCmd},
+	"import":  {importUsage, importCmd},
+	"certs":   {certsUsage, certsCmd},
+	"teams":   {teamsUsage, teamsCmd},
+	"plan":    {planUsage, planCmd},
+}
+
+type app struct {
+	now  *now.Now
+	opts format.Options
+}
+
+// usageError is returned by commands invoked with invalid arguments
+type usageError struct {
+	usage string
+}
+
+func (e usageError) Error() string {
+	return "usage: gonow " + e.usage
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	fs := flag.NewFlagSet("gonow", flag.ContinueOnError)
+	token := fs.String("token", "", "API token, defaults to $NOW_TOKEN or ~/.now/auth.json")
+	team := fs.String("team", "", "team ID, defaults to $NOW_TEAM or ~/.now/config.json")
+	asJSON := fs.Bool("json", false, "print results as JSON, same as --format json")
+	outFormat := fs.String("format", "table", "output format: table, json, ndjson or template")
+	tmpl := fs.String("template", "", "Go template rendered for each result with --format template")
+	fs.Usage = func() { printUsage(fs) }
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if fs.NArg() == 0 {
+		printUsage(fs)
+		return exitUsage
+	}
+	cmd, ok := commands[fs.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "gonow: unknown command %q\n", fs.Arg(0))
+		printUsage(fs)
+		return exitUsage
+	}
+
+	creds, err := now.LoadCredentials()
+	if *token != "" {
+		creds.Token = *token
+	} else if err != nil {
+		fmt.Fprintln(os.Stderr, "gonow:", err)
+		return exitUnauthorized
+	}
+	if *team != "" {
+		creds.TeamID = *team
+	}
+
+	opts := format.Options{Format: format.Format(*outFormat), Template: *tmpl}
+	if *asJSON {
+		opts.Format = format.JSON
+	}
+	if *tmpl != "" && opts.Format == format.Table {
+		opts.Format = format.Template
+	}
+
+	a := &app{now: now.New(creds.Token), opts: opts}
+	if creds.TeamID != "" {
+		a.now.SetTeamID(creds.TeamID)
+	}
+
+	if err := cmd.run(a, fs.Args()[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "gonow:", err)
+		return exitCode(err)
+	}
+	return exitOK
+}
+
+func printUsage(fs *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, "usage: gonow [flags] COMMAND [ARGS]\n\nCommands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintln(os.Stderr, "  "+commands[name].usage)
+	}
+	fmt.Fprintln(os.Stderr, "\nFlags:")
+	fs.PrintDefaults()
+}
+
+// exitCode maps an error to the process exit code
+func exitCode(err error) int {
+	if _, ok := err.(usageError); ok {
+		return exitUsage
+	}
+	cErr, ok := err.(now.ClientError)
+	if !ok {
+		return exitError
+	}
+	switch s := cErr.StatusCode(); {
+	case s == 401 || s == 403:
+		return exitUnauthorized
+	case s == 404:
+		return exitNotFound
+	case s == 409:
+		return exitConflict
+	case s == 429:
+		return exitRateLimited
+	case s >= 500:
+		return exitServerError
+	default:
+		return exitError
+	}
+}
+
+// print renders v in the requested output format
+func (a *app) print(v interface{}) error {
+	return format.Render(os.Stdout, v, a.opts)
+}
+
+// keyValues collects repeated KEY=VALUE flags
+type keyValues map[string]string
+
+func (kv keyValues) String() string {
+	var pairs []string
+	for k, v := range kv {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (kv keyValues) Set(s string) error {
+	parts := strings.SplitN(s, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return errors.New("expected KEY=VALUE")
+	}
+	kv[parts[0]] = parts[1]
+	return nil
+}
@@ -0,0 +1,161 @@
+package now
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Environment variables read by LoadCredentials
+const (
+	EnvToken = "NOW_TOKEN"
+	EnvTeam  = "NOW_TEAM"
+)
+
+// Credentials holds a token and optional team along with where each was found
+type Credentials struct {
+	Token       string
+	TeamID      string
+	TokenSource string
+	TeamSource  string
+}
+
+// LoadCredentials resolves the token from NOW_TOKEN, then from the Now CLI's
+// ~/.now/auth.json, and the team from NOW_TEAM, then from ~/.now/config.json.
+// A missing team is not an error; a missing token is reported along with
+// every source that was checked.
+func LoadCredentials() (Credentials, error) {
+	creds := Credentials{}
+	var checked []string
+	dir := nowConfigDir()
+
+	if t := os.Getenv(EnvToken); t != "" {
+		creds.Token = t
+		creds.TokenSource = "$" + EnvToken
+	} else {
+		checked = append(checked, "$"+EnvToken)
+		authPath := filepath.Join(dir, "auth.json")
+		t, err := readAuthToken(authPath)
+		if err != nil {
+			return creds, err
+		}
+		if t != "" {
+			creds.Token = t
+			creds.TokenSource = authPath
+		} else {
+			checked = append(checked, authPath)
+		}
+	}
+	if creds.Token == "" {
+		return creds, errors.New("no Now token found, checked " + strings.Join(checked, ", "))
+	}
+
+	if t := os.Getenv(EnvTeam); t != "" {
+		creds.TeamID = t
+		creds.TeamSource = "$" + EnvTeam
+	} else {
+		configPath := filepath.Join(dir, "config.json")
+		t, err := readCurrentTeam(configPath)
+		if err != nil {
+			return creds, err
+		}
+		if t != "" {
+			creds.TeamID = t
+			creds.TeamSource = configPath
+		}
+	}
+	return creds, nil
+}
+
+// FromEnvironment returns an authenticated Now api client using the
+// credentials resolved by LoadCredentials
+func FromEnvironment() (*Now, error) {
+	creds, err := LoadCredentials()
+	if err != nil {
+		return nil, err
+	}
+	n := New(creds.Token)
+	if creds.TeamID != "" {
+		n.SetTeamID(creds.TeamID)
+	}
+	return n, nil
+}
+
+func nowConfigDir() string {
+	home := os.Getenv("HOME")
+	if home == "" {
+		home = os.Getenv("USERPROFILE")
+	}
+	return filepath.Join(home, ".now")
+}
+
+// readAuthToken reads the token of the "sh" provider from a Now CLI auth file
+func readAuthToken(p string) (string, error) {
+	var auth struct {
+		Token       string `json:"token"`
+		Credentials []struct {
+			Provider string `json:"provider"`
+			Token    string `json:"token"`
+		} `json:"credentials"`
+	}
+	if ok, err := readJSONFile(p, &auth); !ok {
+		return "", err
+	}
+	if auth.Token != "" {
+		return auth.Token, nil
+	}
+	for _, c := range auth.Credentials {
+		if c.Provider == "sh" {
+			return c.Token, nil
+		}
+	}
+	return "", nil
+}
+
+// readCurrentTeam reads the current team from a Now CLI config file, where it
+// is stored either at the top level or under the "sh" provider, as an ID or
+// a team object
+func readCurrentTeam(p string) (string, error) {
+	var config struct {
+		CurrentTeam json.RawMessage `json:"currentTeam"`
+		Sh          struct {
+			CurrentTeam json.RawMessage `json:"currentTeam"`
+		} `json:"sh"`
+	}
+	if ok, err := readJSONFile(p, &config); !ok {
+		return "", err
+	}
+	for _, raw := range []json.RawMessage{config.CurrentTeam, config.Sh.CurrentTeam} {
+		if len(raw) == 0 {
+			continue
+		}
+		var id string
+		if err := json.Unmarshal(raw, &id); err == nil && id != "" {
+			return id, nil
+		}
+		var team Team
+		if err := json.Unmarshal(raw, &team); err == nil && team.ID != "" {
+			return team.ID, nil
+		}
+	}
+	return "", nil
+}
+
+// readJSONFile decodes the file at p into v, returning false when the file
+// does not exist or cannot be decoded
+func readJSONFile(p string, v interface{}) (bool, error) {
+	b, err := ioutil.ReadFile(p)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return false, errors.New("invalid " + p + ": " + err.Error())
+	}
+	return true, nil
+}
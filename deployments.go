@@ -1,6 +1,8 @@
 package now
 
 import (
+	"encoding/json"
+	"errors"
 	"time"
 )
 
@@ -13,12 +15,80 @@ type IncompleteDeployment struct {
 	Warnings  []string `json:"warnings"`
 }
 
+// Deployment states
+const (
+	StateInitializing = "INITIALIZING"
+	StateDeploying    = "DEPLOYING"
+	StateBooting      = "BOOTING"
+	StateReady        = "READY"
+	StateError        = "DEPLOYMENT_ERROR"
+	StateFrozen       = "FROZEN"
+)
+
 // Deployment is the contents of a deploy object
 type Deployment struct {
-	UID            string     `json:"uid"`
-	Host           string     `json:"host"`
-	State          string     `json:"state"`
-	StateTimestamp *time.Time `json:"stateTs,omitempty"`
+	UID             string             `json:"uid"`
+	Name            string             `json:"name,omitempty"`
+	URL             string             `json:"url,omitempty"`
+	Host            string             `json:"host"`
+	Type            string             `json:"type,omitempty"`
+	State           string             `json:"state"`
+	StateTimestamp  *Timestamp         `json:"stateTs,omitempty"`
+	Created         *Timestamp         `json:"created,omitempty"`
+	Creator         *DeploymentCreator `json:"creator,omitempty"`
+	OwnerID         string             `json:"ownerId,omitempty"`
+	Public          bool               `json:"public,omitempty"`
+	Version         int                `json:"version,omitempty"`
+	Scale           *DeploymentScale   `json:"scale,omitempty"`
+	Regions         []string           `json:"regions,omitempty"`
+	Aliases         []string           `json:"aliases,omitempty"`
+	Meta            map[string]string  `json:"meta,omitempty"`
+	Env             []string           `json:"env,omitempty"`
+	BuildEnv        []string           `json:"buildEnv,omitempty"`
+	Engines         map[string]string  `json:"engines,omitempty"`
+	SessionAffinity string             `json:"sessionAffinity,omitempty"`
+
+	// Extra holds the fields returned by the API which are not modelled
+	// above, keyed by their JSON name
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler, keeping unknown fields in Extra
+func (d *Deployment) UnmarshalJSON(b []byte) error {
+	type deployment Deployment
+	extra, err := unmarshalWithExtra(b, (*deployment)(d))
+	d.Extra = extra
+	return err
+}
+
+// MarshalJSON implements json.Marshaler, writing back the fields in Extra
+func (d Deployment) MarshalJSON() ([]byte, error) {
+	type deployment Deployment
+	return marshalWithExtra(deployment(d), d.Extra)
+}
+
+// DeploymentCreator identifies the user who created a deployment
+type DeploymentCreator struct {
+	UID      string `json:"uid"`
+	Email    string `json:"email,omitempty"`
+	Username string `json:"username,omitempty"`
+}
+
+// DeploymentScale is the current and allowed number of instances of a
+// deployment
+type DeploymentScale struct {
+	Current int `json:"current"`
+	Min     int `json:"min"`
+	Max     int `json:"max"`
+}
+
+// DeploymentInstance is a single running instance of a deployment
+type DeploymentInstance struct {
+	UID     string     `json:"uid"`
+	URL     string     `json:"url,omitempty"`
+	Region  string     `json:"region,omitempty"`
+	State   string     `json:"state,omitempty"`
+	Created *Timestamp `json:"created,omitempty"`
 }
 
 // DeploymentContentType represents a DeploymentContent type string
@@ -43,6 +113,57 @@ type DeploymentDir struct {
 	Children []DeploymentContent   `json:"children"`
 }
 
+// UnmarshalJSON decodes the children of the directory into their concrete
+// DeploymentContent types
+func (d *DeploymentDir) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Type     DeploymentContentType `json:"type"`
+		Name     string                `json:"name"`
+		Children []json.RawMessage     `json:"children"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	children, err := decodeDeploymentContents(raw.Children)
+	if err != nil {
+		return err
+	}
+	d.Type = raw.Type
+	d.Name = raw.Name
+	d.Children = children
+	return nil
+}
+
+func decodeDeploymentContents(raws []json.RawMessage) ([]DeploymentContent, error) {
+	var contents []DeploymentContent
+	for _, r := range raws {
+		var obj struct {
+			Type DeploymentContentType `json:"type"`
+		}
+
+		// Extract the type field
+		if err := json.Unmarshal(r, &obj); err != nil {
+			return contents, err
+		}
+
+		// Unmarshal into appropriate type
+		var content DeploymentContent
+		switch obj.Type {
+		case TypeDir:
+			content = &DeploymentDir{}
+		case TypeFile:
+			content = &DeploymentFile{}
+		default:
+			return contents, errors.New("Unknown file type")
+		}
+		if err := json.Unmarshal(r, content); err != nil {
+			return contents, err
+		}
+		contents = append(contents, content)
+	}
+	return contents, nil
+}
+
 // GetName implements the DeploymentContent interface
 func (d DeploymentDir) GetName() string {
 	return d.Name
@@ -76,8 +197,64 @@ func (d DeploymentFile) GetType() DeploymentContentType {
 
 // Alias represents a deployment alias object
 type Alias struct {
-	UID     string     `json:"uid,omitempty"`
-	OldUID  string     `json:"oldId,omitempty"`
-	Alias   string     `json:"alias"`
-	Created *time.Time `json:"created,omitempty"`
+	UID          string     `json:"uid,omitempty"`
+	OldUID       string     `json:"oldId,omitempty"`
+	Alias        string     `json:"alias"`
+	DeploymentID string     `json:"deploymentId,omitempty"`
+	Created      *time.Time `json:"created,omitempty"`
+}
+
+// DeploymentEventType represents a DeploymentEvent type string
+type DeploymentEventType string
+
+// DeploymentEventTypes
+const (
+	EventCommand         DeploymentEventType = "command"
+	EventStdout          DeploymentEventType = "stdout"
+	EventStderr          DeploymentEventType = "stderr"
+	EventExit            DeploymentEventType = "exit"
+	EventDeploymentState DeploymentEventType = "deployment-state"
+)
+
+// DeploymentEvent is a single build or runtime event of a deployment
+type DeploymentEvent struct {
+	Type       DeploymentEventType
+	Timestamp  time.Time
+	Text       string
+	InstanceID string
+}
+
+// IsLog returns whether the event carries a line of build or runtime output
+func (e DeploymentEvent) IsLog() bool {
+	return e.Type == EventStdout || e.Type == EventStderr || e.Type == EventCommand
+}
+
+// deploymentEventResponse is the wire format of a DeploymentEvent, with
+// timestamps expressed in milliseconds since the epoch
+type deploymentEventResponse struct {
+	Type    DeploymentEventType `json:"type"`
+	Created int64               `json:"created"`
+	Text    string              `json:"text"`
+	Payload struct {
+		Text       string `json:"text"`
+		InstanceID string `json:"instanceId"`
+		Date       int64  `json:"date"`
+	} `json:"payload"`
+}
+
+func (r deploymentEventResponse) event() DeploymentEvent {
+	e := DeploymentEvent{
+		Type:       r.Type,
+		Text:       r.Payload.Text,
+		InstanceID: r.Payload.InstanceID,
+	}
+	if e.Text == "" {
+		e.Text = r.Text
+	}
+	ts := r.Payload.Date
+	if ts == 0 {
+		ts = r.Created
+	}
+	e.Timestamp = time.Unix(0, ts*int64(time.Millisecond))
+	return e
 }
@@ -1,11 +1,16 @@
 package now
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"net/url"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 )
 
 const (
@@ -13,11 +18,13 @@ const (
 	endpointCreateDeployment = "/now/create"
 	endpointDeployments      = "/now/deployments"
 	endpointDeploymentsID    = "/now/deployments/%s"
+	endpointAliases          = "/now/aliases"
 )
 
 // DeploymentsClient contains the methods for the Deployment API
 type DeploymentsClient struct {
-	client *Client
+	client  *Client
+	history *aliasHistory
 }
 
 // New creates a new Deployment
@@ -30,14 +37,114 @@ func (c DeploymentsClient) New(params DeploymentParams) (IncompleteDeployment, C
 
 // DeploymentParams contains all fields necessary to create a deployment
 type DeploymentParams struct {
-	Env         map[string]string `json:"env"`
-	Public      bool              `json:"public"`
-	ForceNew    bool              `json:"forceNew"`
-	ForceSync   bool              `json:"forceSync"`
-	Name        string            `json:"name"`
-	Description string            `json:"description"`
-	Type        string            `json:"deploymentType"`
-	Files       []FileInfo        `json:"files"`
+	Env             map[string]string      `json:"env"`
+	Public          bool                   `json:"public"`
+	ForceNew        bool                   `json:"forceNew"`
+	ForceSync       bool                   `json:"forceSync"`
+	Name            string                 `json:"name"`
+	Description     string                 `json:"description"`
+	Type            string                 `json:"deploymentType"`
+	Files           []FileInfo             `json:"files"`
+	Regions         []string               `json:"regions,omitempty"`
+	Scale           map[string]ScaleParams `json:"scale,omitempty"`
+	Meta            map[string]string      `json:"meta,omitempty"`
+	Engines         map[string]string      `json:"engines,omitempty"`
+	Build           *BuildParams           `json:"build,omitempty"`
+	SessionAffinity string                 `json:"sessionAffinity,omitempty"`
+
+	// Extra holds additional fields sent as-is, keyed by their JSON name, for
+	// API options not modelled above
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// MarshalJSON implements json.Marshaler, adding the fields in Extra
+func (p DeploymentParams) MarshalJSON() ([]byte, error) {
+	type params DeploymentParams
+	return marshalWithExtra(params(p), p.Extra)
+}
+
+// BuildParams contains the settings applied while building a deployment
+type BuildParams struct {
+	Env map[string]string `json:"env,omitempty"`
+}
+
+// Session affinity strategies
+const (
+	SessionAffinityIP     = "ip"
+	SessionAffinityRandom = "random"
+)
+
+// Deploy creates a deployment from the project in dir and uploads any file
+// the API reports as missing. Files are selected according to the project's
+// PackageType, which also sets params.Type when it is empty.
+func (c DeploymentsClient) Deploy(dir string, params DeploymentParams) (IncompleteDeployment, ClientError) {
+	dir = filepath.Clean(dir)
+	pkgType, files, hashes, err := projectFiles(dir)
+	if err != nil {
+		return IncompleteDeployment{}, NewError(err.Error())
+	}
+
+	if params.Type == "" {
+		params.Type = strings.ToUpper(pkgType)
+	}
+	params.Files = *files
+	d, cErr := c.New(params)
+	if cErr != nil {
+		return d, cErr
+	}
+
+	for _, sha := range d.Missing {
+		fh, ok := (*hashes)[sha]
+		if !ok {
+			return d, NewError("Unknown missing file " + sha)
+		}
+		if cErr := c.uploadHash(d.ID, fh); cErr != nil {
+			return d, cErr
+		}
+	}
+	return d, nil
+}
+
+func (c DeploymentsClient) uploadHash(deploymentID string, fh FileHash) ClientError {
+	file, err := os.Open(fh.Path)
+	if err != nil {
+		return NewError(err.Error())
+	}
+	defer file.Close()
+
+	names := make([]string, len(fh.Names))
+	for i, n := range fh.Names {
+		names[i] = n.File
+	}
+	return c.Upload(deploymentID, fh.Sha, names, fh.Names[0].Size, file)
+}
+
+// WaitForState polls a deployment by its ID every interval, two seconds when
+// not positive, until it reaches the given state. It fails early if the
+// deployment errors or ctx is done.
+func (c DeploymentsClient) WaitForState(ctx context.Context, ID, state string, interval time.Duration) (Deployment, ClientError) {
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		d, err := c.Get(ID)
+		if err != nil {
+			return d, err
+		}
+		if d.State == state {
+			return d, nil
+		}
+		if d.State == StateError {
+			return d, NewError(fmt.Sprintf("Deployment %s failed", ID))
+		}
+		select {
+		case <-ctx.Done():
+			return d, NewError(ctx.Err().Error())
+		case <-ticker.C:
+		}
+	}
 }
 
 // Upload performs an upload of the given file to the specified deployment
@@ -75,6 +182,17 @@ type ScaleParams struct {
 	Max int `json:"max"`
 }
 
+// Instances retrieves the running instances of a deployment by its ID
+func (c DeploymentsClient) Instances(ID string) ([]DeploymentInstance, ClientError) {
+	i := &deploymentInstancesResponse{}
+	err := c.client.NewRequest("GET", fmt.Sprintf(endpointDeploymentsID+"/instances", ID), nil, i, nil)
+	return i.Instances, err
+}
+
+type deploymentInstancesResponse struct {
+	Instances []DeploymentInstance `json:"instances"`
+}
+
 // Alias applies the supplied alias to the given deployment ID
 func (c DeploymentsClient) Alias(ID, alias string) (Alias, ClientError) {
 	a := Alias{Alias: alias}
@@ -98,44 +216,270 @@ type deploymentListAliasResponse struct {
 	Aliases []Alias `json:"aliases"`
 }
 
+// AllAliases retrieves every alias under the account
+func (c DeploymentsClient) AllAliases() ([]Alias, ClientError) {
+	a := &deploymentListAliasResponse{}
+	err := c.client.NewRequest("GET", endpointAliases, nil, a, nil)
+	return a.Aliases, err
+}
+
+// Promote points alias at the given deployment once it is READY. The
+// deployment previously behind the alias is returned, nil if the alias is
+// new, and the change is recorded so it can be undone with Rollback.
+func (c DeploymentsClient) Promote(alias, deploymentID string) (*Deployment, ClientError) {
+	d, err := c.Get(deploymentID)
+	if err != nil {
+		return nil, err
+	}
+	if d.State != StateReady {
+		return nil, NewError(fmt.Sprintf("Deployment %s is %s, not %s", deploymentID, d.State, StateReady))
+	}
+
+	prev, err := c.aliasedDeployment(alias)
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := c.Alias(deploymentID, alias)
+	if err != nil {
+		return nil, err
+	}
+
+	change := AliasChange{
+		Alias:        alias,
+		AliasUID:     a.UID,
+		OldAliasUID:  a.OldUID,
+		DeploymentID: deploymentID,
+		Changed:      time.Now(),
+	}
+	if prev != nil {
+		change.PreviousDeploymentID = prev.UID
+	}
+	c.history.push(change)
+	return prev, nil
+}
+
+// Rollback points alias back at the deployment it referenced before the last
+// Promote. When no promotion was recorded, or the alias was moved since by
+// other means, the most recent READY deployment with the same name created
+// before the current one is used instead. The deployment being rolled back is
+// returned so it can be promoted again.
+func (c DeploymentsClient) Rollback(alias string) (*Deployment, ClientError) {
+	current, err := c.aliasedDeployment(alias)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, NewError("Alias " + alias + " does not point to a deployment")
+	}
+
+	var targetID string
+	change, recorded := c.history.last(alias)
+	recorded = recorded && change.DeploymentID == current.UID
+	if recorded && change.PreviousDeploymentID != "" {
+		targetID = change.PreviousDeploymentID
+	} else {
+		target, err := c.previousDeployment(*current)
+		if err != nil {
+			return nil, err
+		}
+		targetID = target.UID
+	}
+
+	target, err := c.Get(targetID)
+	if err != nil {
+		return nil, err
+	}
+	if target.State != StateReady {
+		return nil, NewError(fmt.Sprintf("Deployment %s is %s, not %s", targetID, target.State, StateReady))
+	}
+	if _, err := c.Alias(targetID, alias); err != nil {
+		return nil, err
+	}
+	if recorded {
+		c.history.pop(alias)
+	}
+	return current, nil
+}
+
+// AliasHistory returns the changes recorded by Promote for the given alias,
+// most recent last. The history is kept in memory by the client, so it only
+// covers promotions made by this process.
+func (c DeploymentsClient) AliasHistory(alias string) []AliasChange {
+	return c.history.list(alias)
+}
+
+func (c DeploymentsClient) aliasedDeployment(alias string) (*Deployment, ClientError) {
+	aliases, err := c.AllAliases()
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range aliases {
+		if a.Alias == alias && a.DeploymentID != "" {
+			d, err := c.Get(a.DeploymentID)
+			if err != nil {
+				return nil, err
+			}
+			return &d, nil
+		}
+	}
+	return nil, nil
+}
+
+func (c DeploymentsClient) previousDeployment(current Deployment) (*Deployment, ClientError) {
+	deployments, err := c.List()
+	if err != nil {
+		return nil, err
+	}
+	var prev *Deployment
+	for i, d := range deployments {
+		if d.UID == current.UID || d.Name != current.Name || d.State != StateReady {
+			continue
+		}
+		if d.Created == nil || current.Created == nil || !d.Created.Before(current.Created.Time) {
+			continue
+		}
+		if prev == nil || d.Created.After(prev.Created.Time) {
+			prev = &deployments[i]
+		}
+	}
+	if prev == nil {
+		return nil, NewError("No previous deployment found for " + current.Name)
+	}
+	return prev, nil
+}
+
 // Files retrieves files of a deployment by its ID
 func (c DeploymentsClient) Files(ID string) ([]DeploymentContent, ClientError) {
-	var contents []DeploymentContent
 	var resp []json.RawMessage
 	err := c.client.NewRequest("GET", fmt.Sprintf(endpointDeploymentsID+"/files", ID), nil, &resp, nil)
-	for _, r := range resp {
-		var obj map[string]interface{}
+	if err != nil {
+		return nil, err
+	}
+	contents, dErr := decodeDeploymentContents(resp)
+	if dErr != nil {
+		return contents, NewError(dErr.Error())
+	}
+	return contents, nil
+}
 
-		// Extract the type field
-		err := json.Unmarshal(r, &obj)
-		if err != nil {
-			return contents, NewError(err.Error())
-		}
+// FileContent retrieves the contents of a deployment file by its UID. The
+// caller must close the returned reader.
+func (c DeploymentsClient) FileContent(deploymentID, fileUID string) (io.ReadCloser, ClientError) {
+	if fileUID == "" {
+		return nil, NewError("A file UID is required")
+	}
+	return c.client.NewStreamRequest(context.Background(), "GET", fmt.Sprintf(endpointDeploymentsID+"/files/%s", deploymentID, fileUID), nil)
+}
+
+// Mirror reconstructs the source of a deployment under dir, creating it if
+// needed
+func (c DeploymentsClient) Mirror(deploymentID, dir string) ClientError {
+	contents, err := c.Files(deploymentID)
+	if err != nil {
+		return err
+	}
+	return c.mirrorContents(deploymentID, dir, contents)
+}
 
-		// Unmarshal into appropriate type
-		var content DeploymentContent
-		switch obj["type"].(string) {
-		case "directory":
-			content = &DeploymentDir{}
-		case "file":
-			content = &DeploymentFile{}
-		default:
-			return contents, NewError("Unknown file type")
+func (c DeploymentsClient) mirrorContents(deploymentID, dir string, contents []DeploymentContent) ClientError {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return NewError(err.Error())
+	}
+	for _, content := range contents {
+		name := content.GetName()
+		if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+			return NewError("Invalid file name: " + name)
 		}
-		err = json.Unmarshal(r, &content)
-		if err != nil {
-			return contents, NewError(err.Error())
+		p := filepath.Join(dir, name)
+
+		switch f := content.(type) {
+		case *DeploymentDir:
+			if err := c.mirrorContents(deploymentID, p, f.Children); err != nil {
+				return err
+			}
+		case *DeploymentFile:
+			if err := c.mirrorFile(deploymentID, f.UID, p); err != nil {
+				return err
+			}
 		}
-		contents = append(contents, content)
 	}
-	return contents, err
+	return nil
+}
+
+func (c DeploymentsClient) mirrorFile(deploymentID, fileUID, p string) ClientError {
+	body, err := c.FileContent(deploymentID, fileUID)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	file, fErr := os.Create(p)
+	if fErr != nil {
+		return NewError(fErr.Error())
+	}
+	if _, fErr := io.Copy(file, body); fErr != nil {
+		file.Close()
+		return NewError(fErr.Error())
+	}
+	if fErr := file.Close(); fErr != nil {
+		return NewError(fErr.Error())
+	}
+	return nil
 }
 
-// List retrieves a list of all the deployments under the account
-func (c DeploymentsClient) List() ([]Deployment, ClientError) {
+// List retrieves a list of all the deployments under the account. When
+// selectors are given only deployments matching all of them are returned;
+// they are sent to the API and applied again locally for servers which
+// ignore them.
+func (c DeploymentsClient) List(selectors ...MetaSelector) ([]Deployment, ClientError) {
 	d := &deploymentListResponse{}
-	err := c.client.NewRequest("GET", endpointDeployments, nil, d, nil)
-	return d.Deployments, err
+	path := endpointDeployments
+	if len(selectors) > 0 {
+		q := url.Values{}
+		for _, s := range selectors {
+			q.Add("meta-"+s.Key, s.Value)
+		}
+		path += "?" + q.Encode()
+	}
+	err := c.client.NewRequest("GET", path, nil, d, nil)
+	if err != nil || len(selectors) == 0 {
+		return d.Deployments, err
+	}
+
+	var matched []Deployment
+	for _, dep := range d.Deployments {
+		if matchesAll(selectors, dep) {
+			matched = append(matched, dep)
+		}
+	}
+	return matched, nil
+}
+
+// MetaSelector matches deployments whose Meta has Key set to Value
+type MetaSelector struct {
+	Key   string
+	Value string
+}
+
+// MetaEquals returns a MetaSelector for the given key and value
+func MetaEquals(key, value string) MetaSelector {
+	return MetaSelector{Key: key, Value: value}
+}
+
+// Matches returns whether the deployment satisfies the selector
+func (s MetaSelector) Matches(d Deployment) bool {
+	v, ok := d.Meta[s.Key]
+	return ok && v == s.Value
+}
+
+func matchesAll(selectors []MetaSelector, d Deployment) bool {
+	for _, s := range selectors {
+		if !s.Matches(d) {
+			return false
+		}
+	}
+	return true
 }
 
 type deploymentListResponse struct {
@@ -146,3 +490,62 @@ type deploymentListResponse struct {
 func (c DeploymentsClient) Delete(ID string) ClientError {
 	return c.client.NewRequest("DELETE", fmt.Sprintf(endpointDeploymentsID, ID), nil, nil, nil)
 }
+
+// Events streams the build and runtime events of a deployment, following new
+// events as they are emitted. Both channels are closed once the stream ends
+// or ctx is cancelled; at most one error is sent.
+func (c DeploymentsClient) Events(ctx context.Context, ID string) (<-chan DeploymentEvent, <-chan ClientError) {
+	events := make(chan DeploymentEvent)
+	errs := make(chan ClientError, 1)
+
+	go func() {
+		defer close(events)
+		defer close(errs)
+
+		body, err := c.client.NewStreamRequest(ctx, "GET", fmt.Sprintf(endpointDeploymentsID+"/events?follow=1", ID), nil)
+		if err != nil {
+			errs <- err
+			return
+		}
+		defer body.Close()
+
+		dec := json.NewDecoder(body)
+		for {
+			r := deploymentEventResponse{}
+			if err := dec.Decode(&r); err != nil {
+				if err != io.EOF && ctx.Err() == nil {
+					errs <- NewError(err.Error())
+				}
+				return
+			}
+			select {
+			case events <- r.event():
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events, errs
+}
+
+// Logs retrieves the build and runtime log lines emitted so far by a deployment
+func (c DeploymentsClient) Logs(ctx context.Context, ID string) ([]DeploymentEvent, ClientError) {
+	var logs []DeploymentEvent
+	body, err := c.client.NewStreamRequest(ctx, "GET", fmt.Sprintf(endpointDeploymentsID+"/events", ID), nil)
+	if err != nil {
+		return logs, err
+	}
+	defer body.Close()
+
+	var resp []deploymentEventResponse
+	if err := json.NewDecoder(body).Decode(&resp); err != nil {
+		return logs, NewError(err.Error())
+	}
+	for _, r := range resp {
+		if e := r.event(); e.IsLog() {
+			logs = append(logs, e)
+		}
+	}
+	return logs, nil
+}
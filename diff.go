@@ -0,0 +1,120 @@
+package now
+
+import (
+	"path"
+	"path/filepath"
+	"sort"
+)
+
+// FileChangeType represents a FileChange type string
+type FileChangeType string
+
+// FileChangeTypes
+const (
+	FileAdded   FileChangeType = "added"
+	FileRemoved FileChangeType = "removed"
+	FileChanged FileChangeType = "changed"
+)
+
+// FileChange is a file which differs between two sets of deployment files
+type FileChange struct {
+	Path   string         `json:"path"`
+	Type   FileChangeType `json:"type"`
+	OldSha string         `json:"oldSha,omitempty"`
+	NewSha string         `json:"newSha,omitempty"`
+}
+
+// Diff reports the files added, removed and changed from deployment a to
+// deployment b, sorted by path. Files are compared by SHA; a file present in
+// both whose SHA is unknown on either side is assumed unchanged.
+func (c DeploymentsClient) Diff(a, b string) ([]FileChange, ClientError) {
+	oldFiles, err := c.fileShas(a)
+	if err != nil {
+		return nil, err
+	}
+	newFiles, err := c.fileShas(b)
+	if err != nil {
+		return nil, err
+	}
+	return diffFiles(oldFiles, newFiles), nil
+}
+
+// DiffDir reports the files added, removed and changed from a deployment to
+// the project in dir, selecting local files the same way Deploy does
+func (c DeploymentsClient) DiffDir(deploymentID, dir string) ([]FileChange, ClientError) {
+	oldFiles, err := c.fileShas(deploymentID)
+	if err != nil {
+		return nil, err
+	}
+	_, files, _, fErr := projectFiles(filepath.Clean(dir))
+	if fErr != nil {
+		return nil, NewError(fErr.Error())
+	}
+	newFiles := make(map[string]string, len(*files))
+	for _, f := range *files {
+		newFiles[filepath.ToSlash(f.File)] = f.Sha
+	}
+	return diffFiles(oldFiles, newFiles), nil
+}
+
+// fileShas flattens the file tree of a deployment into a map of paths to
+// SHAs, the SHA being empty when the file UID is not one
+func (c DeploymentsClient) fileShas(ID string) (map[string]string, ClientError) {
+	contents, err := c.Files(ID)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]string)
+	flattenContents("", contents, files)
+	return files, nil
+}
+
+func flattenContents(dir string, contents []DeploymentContent, files map[string]string) {
+	for _, content := range contents {
+		p := path.Join(dir, content.GetName())
+		switch f := content.(type) {
+		case *DeploymentDir:
+			flattenContents(p, f.Children, files)
+		case *DeploymentFile:
+			if isSha(f.UID) {
+				files[p] = f.UID
+			} else {
+				files[p] = ""
+			}
+		}
+	}
+}
+
+func isSha(s string) bool {
+	if len(s) != 40 {
+		return false
+	}
+	for _, r := range s {
+		if !('0' <= r && r <= '9' || 'a' <= r && r <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func diffFiles(oldFiles, newFiles map[string]string) []FileChange {
+	var changes []FileChange
+	for p, oldSha := range oldFiles {
+		newSha, ok := newFiles[p]
+		switch {
+		case !ok:
+			changes = append(changes, FileChange{Path: p, Type: FileRemoved, OldSha: oldSha})
+		case oldSha != "" && newSha != "" && oldSha != newSha:
+			changes = append(changes, FileChange{Path: p, Type: FileChanged, OldSha: oldSha, NewSha: newSha})
+		}
+	}
+	for p, newSha := range newFiles {
+		if _, ok := oldFiles[p]; !ok {
+			changes = append(changes, FileChange{Path: p, Type: FileAdded, NewSha: newSha})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes
+}
@@ -10,3 +10,24 @@ type Domain struct {
 	VerifyToken string     `json:"verifyToken,omitempty"`
 	Created     *time.Time `json:"created,omitempty"`
 }
+
+// DomainStatus reports whether a domain is available for purchase
+type DomainStatus struct {
+	Available bool `json:"available"`
+}
+
+// DomainPrice is the cost of buying a domain for Period years
+type DomainPrice struct {
+	Price  int `json:"price"`
+	Period int `json:"period"`
+}
+
+// DNSRecord is the contents of a DNS record object
+type DNSRecord struct {
+	ID      string     `json:"id"`
+	Slug    string     `json:"slug,omitempty"`
+	Type    string     `json:"type"`
+	Name    string     `json:"name"`
+	Value   string     `json:"value"`
+	Created *time.Time `json:"created,omitempty"`
+}
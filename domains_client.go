@@ -2,6 +2,7 @@ package now
 
 import (
 	"fmt"
+	"net/url"
 )
 
 const domainsEndpoint = "/domains"
@@ -43,7 +44,94 @@ type domainListResponse struct {
 	Domains []Domain `json:"domains"`
 }
 
+// Get retrieves a domain by its name
+func (c DomainsClient) Get(domainName string) (Domain, ClientError) {
+	d := domainResponse{}
+	err := c.client.NewRequest("GET", fmt.Sprintf("%s/%s", domainsEndpoint, domainName), nil, &d, nil)
+	return d.Domain, err
+}
+
+type domainResponse struct {
+	Domain Domain `json:"domain"`
+}
+
+// Verify asks the API to check the verification record of an external domain
+func (c DomainsClient) Verify(domainName string) (Domain, ClientError) {
+	d := domainResponse{}
+	err := c.client.NewRequest("POST", fmt.Sprintf("%s/%s/verify", domainsEndpoint, domainName), nil, &d, nil)
+	return d.Domain, err
+}
+
+// Status returns whether a domain is available for purchase
+func (c DomainsClient) Status(domainName string) (DomainStatus, ClientError) {
+	s := DomainStatus{}
+	err := c.client.NewRequest("GET", domainsEndpoint+"/status?name="+url.QueryEscape(domainName), nil, &s, nil)
+	return s, err
+}
+
+// Price returns the cost of buying a domain
+func (c DomainsClient) Price(domainName string) (DomainPrice, ClientError) {
+	p := DomainPrice{}
+	err := c.client.NewRequest("GET", domainsEndpoint+"/price?name="+url.QueryEscape(domainName), nil, &p, nil)
+	return p, err
+}
+
+// Buy purchases a domain. expectedPrice must equal the current Price of the
+// domain, otherwise nothing is charged and an error is returned.
+func (c DomainsClient) Buy(domainName string, expectedPrice int) ClientError {
+	p, err := c.Price(domainName)
+	if err != nil {
+		return err
+	}
+	if p.Price != expectedPrice {
+		return NewError(fmt.Sprintf("Price of %s is %d, expected %d", domainName, p.Price, expectedPrice))
+	}
+	return c.client.NewRequest("POST", domainsEndpoint+"/buy", DomainBuyParams{
+		Name:          domainName,
+		ExpectedPrice: expectedPrice,
+	}, nil, nil)
+}
+
+// DomainBuyParams contains all fields for domain purchase
+type DomainBuyParams struct {
+	Name          string `json:"name"`
+	ExpectedPrice int    `json:"expectedPrice"`
+}
+
 // Delete deletes the domain by its ID
 func (c DomainsClient) Delete(domainName string) ClientError {
 	return c.client.NewRequest("DELETE", fmt.Sprintf("%s/%s", domainsEndpoint, domainName), nil, nil, nil)
 }
+
+// Records retrieves the DNS records of a domain
+func (c DomainsClient) Records(domainName string) ([]DNSRecord, ClientError) {
+	r := &dnsRecordListResponse{}
+	err := c.client.NewRequest("GET", fmt.Sprintf("%s/%s/records", domainsEndpoint, domainName), nil, r, nil)
+	return r.Records, err
+}
+
+type dnsRecordListResponse struct {
+	Records []DNSRecord `json:"records"`
+}
+
+// NewRecord creates a DNS record for a domain
+func (c DomainsClient) NewRecord(domainName string, params DNSRecordParams) (DNSRecord, ClientError) {
+	r := DNSRecord{}
+	err := c.client.NewRequest("POST", fmt.Sprintf("%s/%s/records", domainsEndpoint, domainName), params, &r, nil)
+	r.Type = params.Type
+	r.Name = params.Name
+	r.Value = params.Value
+	return r, err
+}
+
+// DNSRecordParams contains all fields for DNS record create
+type DNSRecordParams struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// DeleteRecord deletes a DNS record of a domain by its ID
+func (c DomainsClient) DeleteRecord(domainName, recordID string) ClientError {
+	return c.client.NewRequest("DELETE", fmt.Sprintf("%s/%s/records/%s", domainsEndpoint, domainName, recordID), nil, nil, nil)
+}
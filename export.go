@@ -0,0 +1,316 @@
+package now
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ExportVersion is the version of the document produced by Export
+const ExportVersion = 1
+
+// Export is a snapshot of an account's configuration. Secret values and
+// certificate keys cannot be read from the API so only their names and
+// domains are included.
+type Export struct {
+	Version int            `json:"version"`
+	Created time.Time      `json:"created"`
+	TeamID  string         `json:"teamId,omitempty"`
+	Domains []ExportDomain `json:"domains"`
+	Certs   []Cert         `json:"certs"`
+	Aliases []Alias        `json:"aliases"`
+	Secrets []string       `json:"secrets"`
+	Members []TeamMember   `json:"members,omitempty"`
+}
+
+// ExportDomain is an exported domain along with its DNS records
+type ExportDomain struct {
+	Domain  Domain      `json:"domain"`
+	Records []DNSRecord `json:"records"`
+}
+
+// ImportConflict is an exported item which could not be imported as-is
+type ImportConflict struct {
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// ImportReport lists what an Import created and what conflicted
+type ImportReport struct {
+	Created   []string         `json:"created"`
+	Conflicts []ImportConflict `json:"conflicts"`
+}
+
+func (r *ImportReport) created(kind, name string) {
+	r.Created = append(r.Created, kind+" "+name)
+}
+
+func (r *ImportReport) conflict(kind, name, reason string) {
+	r.Conflicts = append(r.Conflicts, ImportConflict{Kind: kind, Name: name, Reason: reason})
+}
+
+// Export snapshots the domains, DNS records, certs, aliases and secret names
+// of the account, and the members of the current team if one is set
+func (n Now) Export() (*Export, ClientError) {
+	doc := &Export{
+		Version: ExportVersion,
+		Created: time.Now().UTC(),
+		TeamID:  n.client.teamID,
+	}
+
+	domains, err := n.Domains.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range domains {
+		// External domains may have no records on Now
+		records, err := n.Domains.Records(d.Name)
+		if err != nil && err.StatusCode() != 404 {
+			return nil, err
+		}
+		doc.Domains = append(doc.Domains, ExportDomain{Domain: d, Records: records})
+	}
+
+	certs, err := n.Certs.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range certs {
+		doc.Certs = append(doc.Certs, *c)
+	}
+
+	if doc.Aliases, err = n.Deployments.AllAliases(); err != nil {
+		return nil, err
+	}
+
+	secrets, err := n.Secrets.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range secrets {
+		doc.Secrets = append(doc.Secrets, s.Name)
+	}
+
+	if doc.TeamID != "" {
+		if doc.Members, err = n.Teams.Members(doc.TeamID); err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
+
+// ReadExport decodes an Export document, rejecting unsupported versions
+func ReadExport(r io.Reader) (*Export, error) {
+	doc := &Export{}
+	if err := json.NewDecoder(r).Decode(doc); err != nil {
+		return nil, err
+	}
+	if doc.Version != ExportVersion {
+		return nil, fmt.Errorf("unsupported export version %d", doc.Version)
+	}
+	return doc, nil
+}
+
+// Import replays an Export into the personal account, or into targetTeam
+// when set, without changing the team of n. Existing items are left
+// untouched; anything that differs or cannot be recreated is reported as a
+// conflict. Domains are added as external so they are not bought again, and
+// their DNS records are recreated on Now so they are in place once the
+// domain's nameservers point to it. Certs are requested anew, secrets need
+// their values set manually and aliases are only recreated for deployments
+// which exist in the target.
+func (n Now) Import(doc *Export, targetTeam string) (*ImportReport, ClientError) {
+	if doc.Version != ExportVersion {
+		return nil, NewError(fmt.Sprintf("Unsupported export version %d", doc.Version))
+	}
+	t := New(n.client.secret)
+	t.client.URL = n.client.URL
+	t.client.HTTPClient = n.client.HTTPClient
+	t.client.teamID = targetTeam
+
+	report := &ImportReport{}
+	steps := []func(*Now, *Export, *ImportReport) ClientError{
+		importDomains,
+		importCerts,
+		importSecrets,
+		importAliases,
+	}
+	if targetTeam != "" {
+		steps = append(steps, func(t *Now, doc *Export, report *ImportReport) ClientError {
+			return importMembers(t, doc, report, targetTeam)
+		})
+	}
+	for _, step := range steps {
+		if err := step(t, doc, report); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+func importDomains(t *Now, doc *Export, report *ImportReport) ClientError {
+	current, err := t.Domains.List()
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]bool, len(current))
+	for _, d := range current {
+		existing[d.Name] = true
+	}
+
+	for _, d := range doc.Domains {
+		name := d.Domain.Name
+		var records []DNSRecord
+		if existing[name] {
+			if records, err = t.Domains.Records(name); err != nil && err.StatusCode() != 404 {
+				return err
+			}
+		} else {
+			if _, err := t.Domains.New(name, true); err != nil {
+				report.conflict("domain", name, err.Error())
+				continue
+			}
+			report.created("domain", name)
+		}
+
+		for _, r := range d.Records {
+			key := strings.TrimPrefix(r.Name+"."+name, ".") + " " + r.Type
+			if findRecord(records, r, true) != nil {
+				continue
+			}
+			if match := findRecord(records, r, false); match != nil && singleValueRecords[strings.ToUpper(r.Type)] {
+				report.conflict("dns", key, fmt.Sprintf("exists with value %s instead of %s", match.Value, r.Value))
+				continue
+			}
+			if _, err := t.Domains.NewRecord(name, DNSRecordParams{Name: r.Name, Type: r.Type, Value: r.Value}); err != nil {
+				report.conflict("dns", key, err.Error())
+				continue
+			}
+			report.created("dns", key)
+		}
+	}
+	return nil
+}
+
+// singleValueRecords are the DNS record types a name can only have one of
+var singleValueRecords = map[string]bool{
+	"CNAME": true,
+	"ALIAS": true,
+}
+
+// findRecord returns the record with the name and type of r, and its value
+// when withValue is set
+func findRecord(records []DNSRecord, r DNSRecord, withValue bool) *DNSRecord {
+	for i, c := range records {
+		if c.Name == r.Name && strings.EqualFold(c.Type, r.Type) && (!withValue || c.Value == r.Value) {
+			return &records[i]
+		}
+	}
+	return nil
+}
+
+func importCerts(t *Now, doc *Export, report *ImportReport) ClientError {
+	current, err := t.Certs.List()
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]bool)
+	for _, c := range current {
+		for _, cn := range c.CommonNames {
+			existing[cn] = true
+		}
+	}
+
+	for _, c := range doc.Certs {
+		var missing []string
+		for _, cn := range c.CommonNames {
+			if !existing[cn] {
+				missing = append(missing, cn)
+			}
+		}
+		if len(missing) == 0 {
+			continue
+		}
+		name := strings.Join(missing, ",")
+		if _, err := t.Certs.New(missing); err != nil {
+			report.conflict("cert", name, err.Error())
+			continue
+		}
+		report.created("cert", name)
+	}
+	return nil
+}
+
+func importSecrets(t *Now, doc *Export, report *ImportReport) ClientError {
+	current, err := t.Secrets.List()
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]bool, len(current))
+	for _, s := range current {
+		existing[s.Name] = true
+	}
+	for _, name := range doc.Secrets {
+		if !existing[name] {
+			report.conflict("secret", name, "value is not exported, create it manually")
+		}
+	}
+	return nil
+}
+
+func importAliases(t *Now, doc *Export, report *ImportReport) ClientError {
+	current, err := t.Deployments.AllAliases()
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]string, len(current))
+	for _, a := range current {
+		existing[a.Alias] = a.DeploymentID
+	}
+
+	for _, a := range doc.Aliases {
+		target, ok := existing[a.Alias]
+		switch {
+		case ok && target == a.DeploymentID:
+			continue
+		case ok:
+			report.conflict("alias", a.Alias, "points to deployment "+target)
+			continue
+		}
+		if _, err := t.Deployments.Get(a.DeploymentID); err != nil {
+			report.conflict("alias", a.Alias, "deployment "+a.DeploymentID+" does not exist in the target")
+			continue
+		}
+		if _, err := t.Deployments.Alias(a.DeploymentID, a.Alias); err != nil {
+			report.conflict("alias", a.Alias, err.Error())
+			continue
+		}
+		report.created("alias", a.Alias)
+	}
+	return nil
+}
+
+func importMembers(t *Now, doc *Export, report *ImportReport, teamID string) ClientError {
+	current, err := t.Teams.Members(teamID)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]bool, len(current))
+	for _, m := range current {
+		existing[m.Email] = true
+	}
+	for _, m := range doc.Members {
+		if m.Email == "" || existing[m.Email] {
+			continue
+		}
+		if err := t.Teams.InviteUser(teamID, m.Email); err != nil {
+			report.conflict("member", m.Email, err.Error())
+			continue
+		}
+		report.created("member", m.Email)
+	}
+	return nil
+}
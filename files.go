@@ -6,6 +6,7 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 
@@ -58,100 +59,199 @@ func PackageType(dir string) string {
 	return ""
 }
 
-// StaticFiles returns an array of paths for a given static project
-func StaticFiles(dir string) (*[]string, error) {
-	// TODO: make compliant with all config and ignore options
-	ignoreFiles := append([]string{}, defaultIgnorePaths...)
+// FileSelector describes the rules used to pick the files of a project for
+// deployment. Patterns use the same glob syntax as ignore files; patterns
+// without a slash match the base name, patterns with a slash match the path
+// relative to the project directory and a trailing slash only matches
+// directories. As in .gitignore, exclude patterns apply in order and a
+// pattern starting with "!" re-includes what earlier ones excluded, except
+// for files within an excluded directory.
+type FileSelector struct {
+	// Include limits the selection to files matching at least one pattern,
+	// or within a directory matching one. When empty every file not
+	// excluded is selected.
+	Include []string
+	// Exclude lists patterns for files and directories to skip
+	Exclude []string
+	// IgnoreFiles lists ignore files (e.g. .gitignore) read from the project
+	// root whose patterns are appended to Exclude
+	IgnoreFiles []string
+	// MaxFileSize skips files larger than the given number of bytes; zero
+	// means no limit
+	MaxFileSize int64
+	// SkipHidden skips every file and directory whose name starts with a dot
+	SkipHidden bool
+	// Relative returns paths relative to the project directory instead of
+	// joined to it
+	Relative bool
+}
 
-	// Obey gitignore file if exists
-	gitIgnoreFiles, err := readIgnore(dir, ".gitignore")
-	if err != nil {
-		return nil, err
+// NewFileSelector returns a FileSelector excluding the default ignored paths
+// as well as the patterns of the given ignore files
+func NewFileSelector(ignoreFiles ...string) FileSelector {
+	return FileSelector{
+		Exclude:     append([]string{}, defaultIgnorePaths...),
+		IgnoreFiles: ignoreFiles,
 	}
-	ignoreFiles = append(ignoreFiles, gitIgnoreFiles...)
+}
+
+// Select walks dir and returns the paths of every file matching the selector
+func (s FileSelector) Select(dir string) ([]string, error) {
+	exclude := append([]string{}, s.Exclude...)
+	for _, typ := range s.IgnoreFiles {
+		patterns, err := readIgnore(dir, typ)
+		if err != nil {
+			return nil, err
+		}
+		exclude = append(exclude, patterns...)
+	}
+
+	var files []string
+	err := filepath.Walk(dir, func(path string, f os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
 
-	// Walk the directory of files
-	files, err := readDirFiles(dir, ignoreFiles)
+		if (s.SkipHidden && strings.HasPrefix(f.Name(), ".")) || excluded(exclude, rel, f.IsDir()) {
+			if f.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if f.IsDir() {
+			return nil
+		}
+		if len(s.Include) > 0 && !included(s.Include, rel) {
+			return nil
+		}
+		if s.MaxFileSize > 0 && f.Size() > s.MaxFileSize {
+			return nil
+		}
+
+		if s.Relative {
+			files = append(files, rel)
+		} else {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// Files selects the files in dir and returns them ready to be sent as
+// DeploymentParams.Files
+func (s FileSelector) Files(dir string) (*[]FileInfo, *FileHashMap, error) {
+	s.Relative = false
+	paths, err := s.Select(dir)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	return &files, nil
+	return NewFilesList(dir, paths)
+}
+
+// StaticFiles returns an array of paths for a given static project
+func StaticFiles(dir string) (*[]string, error) {
+	return selectFiles(dir, NewFileSelector(".gitignore"))
 }
 
 // DockerFiles returns an array of paths for a given Docker project
 func DockerFiles(dir string) (*[]string, error) {
-	// TODO: make compliant with all config and ignore options
-	ignoreFiles := append([]string{}, defaultIgnorePaths...)
+	return selectFiles(dir, NewFileSelector(".dockerignore", ".gitignore"))
+}
 
-	// Obey dockerignore file if exists
-	dockerIgnoreFiles, err := readIgnore(dir, ".dockerignore")
-	if err != nil {
-		return nil, err
+// projectFiles selects the files of dir according to its PackageType, which
+// is returned with "static" standing for projects of no specific type
+func projectFiles(dir string) (string, *[]FileInfo, *FileHashMap, error) {
+	var paths *[]string
+	var err error
+	pkgType := PackageType(dir)
+	switch pkgType {
+	case "docker":
+		paths, err = DockerFiles(dir)
+	case "npm":
+		paths, err = NpmFiles(dir)
+	default:
+		pkgType = "static"
+		paths, err = StaticFiles(dir)
 	}
-	ignoreFiles = append(ignoreFiles, dockerIgnoreFiles...)
-
-	// Obey gitignore file if exists
-	gitIgnoreFiles, err := readIgnore(dir, ".gitignore")
 	if err != nil {
-		return nil, err
+		return pkgType, nil, nil, err
 	}
-	ignoreFiles = append(ignoreFiles, gitIgnoreFiles...)
+	files, hashes, err := NewFilesList(dir, *paths)
+	return pkgType, files, hashes, err
+}
 
-	// Walk the directory of files
-	files, err := readDirFiles(dir, ignoreFiles)
+func selectFiles(dir string, s FileSelector) (*[]string, error) {
+	files, err := s.Select(dir)
 	if err != nil {
 		return nil, err
 	}
 	return &files, nil
 }
 
-// NpmFiles returns an array of paths for a given npm package
-func NpmFiles(dir string) (*[]string, error) {
-	// TODO: make compliant with all config and ignore options
-	ignoreFiles := append([]string{}, defaultIgnorePaths...)
-
-	// Obey npmignore file if exists
-	npmIgnoreFiles, err := readIgnore(dir, ".npmignore")
-	if err != nil {
-		return nil, err
+func matchAny(patterns []string, rel string, isDir bool) bool {
+	for _, p := range patterns {
+		if matchPattern(p, rel, isDir) {
+			return true
+		}
 	}
-	ignoreFiles = append(ignoreFiles, npmIgnoreFiles...)
+	return false
+}
 
-	// Obey gitignore file if exists
-	gitIgnoreFiles, err := readIgnore(dir, ".gitignore")
-	if err != nil {
-		return nil, err
+// excluded applies patterns in order, the last matching one deciding
+// whether rel is excluded
+func excluded(patterns []string, rel string, isDir bool) bool {
+	ex := false
+	for _, p := range patterns {
+		p = strings.TrimSpace(p)
+		if strings.HasPrefix(p, "!") {
+			if matchPattern(p[1:], rel, isDir) {
+				ex = false
+			}
+		} else if matchPattern(p, rel, isDir) {
+			ex = true
+		}
 	}
-	ignoreFiles = append(ignoreFiles, gitIgnoreFiles...)
+	return ex
+}
 
-	// Walk the directory of files
-	files, err := readDirFiles(dir, ignoreFiles)
-	if err != nil {
-		return nil, err
+// included returns whether the file rel or one of its parent directories
+// matches a pattern
+func included(patterns []string, rel string) bool {
+	if matchAny(patterns, rel, false) {
+		return true
 	}
-
-	return &files, nil
+	for d := path.Dir(rel); d != "." && d != "/"; d = path.Dir(d) {
+		if matchAny(patterns, d, true) {
+			return true
+		}
+	}
+	return false
 }
 
-func readDirFiles(dir string, ignoreFiles []string) ([]string, error) {
-	var files []string
-	err := filepath.Walk(dir, func(path string, f os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if f.IsDir() {
-			return nil
-		}
-		// TODO: error if package.json doesn't have a start or now-start script, or server.js
-		for _, wildcard := range ignoreFiles {
-			if glob.Glob(wildcard, filepath.Base(path)) {
-				return nil
-			}
+func matchPattern(pattern, rel string, isDir bool) bool {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" || strings.HasPrefix(pattern, "#") {
+		return false
+	}
+	if strings.HasSuffix(pattern, "/") {
+		if !isDir {
+			return false
 		}
-		files = append(files, path)
-		return nil
-	})
-	return files, err
+		pattern = strings.TrimSuffix(pattern, "/")
+	}
+	if strings.Contains(pattern, "/") {
+		return glob.Glob(strings.TrimPrefix(pattern, "/"), rel)
+	}
+	return glob.Glob(pattern, path.Base(rel))
 }
 
 func readIgnore(dir, typ string) ([]string, error) {
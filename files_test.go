@@ -0,0 +1,80 @@
+package now
+
+import "testing"
+
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		rel     string
+		isDir   bool
+		want    bool
+	}{
+		{"*.log", "debug.log", false, true},
+		{"*.log", "logs/debug.log", false, true},
+		{"debug.log", "logs/debug.log", false, true},
+		{"/debug.log", "logs/debug.log", false, false},
+		{"/debug.log", "debug.log", false, true},
+		{"logs/*.log", "logs/debug.log", false, true},
+		{"logs/*.log", "app/logs/debug.log", false, false},
+		{"build/", "build", true, true},
+		{"build/", "build", false, false},
+		{"build", "build", false, true},
+		{"# comment", "# comment", false, false},
+		{"", "a", false, false},
+	}
+	for _, tt := range tests {
+		if got := matchPattern(tt.pattern, tt.rel, tt.isDir); got != tt.want {
+			t.Errorf("matchPattern(%q, %q, %v) = %v, want %v", tt.pattern, tt.rel, tt.isDir, got, tt.want)
+		}
+	}
+}
+
+func TestExcluded(t *testing.T) {
+	patterns := []string{"*.log", "!keep.log", "tmp/", "!tmp/keep"}
+	tests := []struct {
+		rel   string
+		isDir bool
+		want  bool
+	}{
+		{"debug.log", false, true},
+		{"keep.log", false, false},
+		{"sub/keep.log", false, false},
+		{"main.go", false, false},
+		{"tmp", true, true},
+		{"tmp", false, false},
+		{"tmp/keep", true, false},
+	}
+	for _, tt := range tests {
+		if got := excluded(patterns, tt.rel, tt.isDir); got != tt.want {
+			t.Errorf("excluded(%q, %v) = %v, want %v", tt.rel, tt.isDir, got, tt.want)
+		}
+	}
+
+	if !excluded([]string{"!keep.log", "*.log"}, "keep.log", false) {
+		t.Error("a negation before the pattern it negates should not apply")
+	}
+}
+
+func TestIncluded(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		rel      string
+		want     bool
+	}{
+		{[]string{"lib"}, "lib/a.js", true},
+		{[]string{"lib"}, "lib/sub/b.js", true},
+		{[]string{"lib/"}, "lib/a.js", true},
+		{[]string{"lib/"}, "lib", false},
+		{[]string{"/lib"}, "lib/sub/b.js", true},
+		{[]string{"/lib"}, "src/lib/a.js", false},
+		{[]string{"lib"}, "src/lib/a.js", true},
+		{[]string{"lib"}, "library.js", false},
+		{[]string{"*.js"}, "src/index.js", true},
+		{[]string{"/index.js"}, "src/index.js", false},
+	}
+	for _, tt := range tests {
+		if got := included(tt.patterns, tt.rel); got != tt.want {
+			t.Errorf("included(%q, %q) = %v, want %v", tt.patterns, tt.rel, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,331 @@
+// Package format renders the resources returned by the go-now clients as
+// aligned tables, JSON, newline-delimited JSON or Go templates.
+package format
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	"github.com/manifoldco/go-now"
+)
+
+// Format represents an output format name
+type Format string
+
+// Formats
+const (
+	Table    Format = "table"
+	JSON     Format = "json"
+	NDJSON   Format = "ndjson"
+	Template Format = "template"
+)
+
+// DefaultColumns lists the table columns used for the go-now resources when
+// Options.Columns is empty. Other types show all their simple fields.
+var DefaultColumns = map[reflect.Type][]string{
+	reflect.TypeOf(now.Deployment{}):   {"UID", "Name", "Host", "State", "Created"},
+	reflect.TypeOf(now.Domain{}):       {"UID", "Name", "Verified", "Created"},
+	reflect.TypeOf(now.Cert{}):         {"UID", "CommonNames", "Expiration", "AutoRenew"},
+	reflect.TypeOf(now.Team{}):         {"ID", "Slug", "Name", "Created"},
+	reflect.TypeOf(now.TeamMember{}):   {"UID", "Username", "Email", "Role"},
+	reflect.TypeOf(now.Alias{}):        {"UID", "Alias", "DeploymentID", "Created"},
+	reflect.TypeOf(now.Subscription{}): {"ID", "Plan.ID", "Status", "CurrentPeriodEnd"},
+}
+
+// Options controls how values are rendered
+type Options struct {
+	Format Format
+	// Template is the text/template executed for each item with the
+	// Template format. The "ago" and "json" functions are available.
+	Template string
+	// Columns selects the struct fields shown by the Table format. Nested
+	// fields are selected with dotted paths such as "Plan.ID".
+	Columns []string
+	// Now is the reference for relative times, defaulting to time.Now
+	Now time.Time
+}
+
+// Render writes v, a single value or a slice of values, to w
+func Render(w io.Writer, v interface{}, opts Options) error {
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	switch opts.Format {
+	case JSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case NDJSON:
+		enc := json.NewEncoder(w)
+		for _, item := range items(v) {
+			if err := enc.Encode(item.Interface()); err != nil {
+				return err
+			}
+		}
+		return nil
+	case Template:
+		return renderTemplate(w, v, opts)
+	case Table, "":
+		return renderTable(w, v, opts)
+	default:
+		return fmt.Errorf("unknown format %q", opts.Format)
+	}
+}
+
+// Ago formats t relative to the reference time, such as "5m ago" or "in 2d"
+func Ago(t, ref time.Time) string {
+	d := ref.Sub(t)
+	suffix := " ago"
+	if d < 0 {
+		d = -d
+		suffix = ""
+	}
+
+	var s string
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		s = fmt.Sprintf("%dm", d/time.Minute)
+	case d < 24*time.Hour:
+		s = fmt.Sprintf("%dh", d/time.Hour)
+	case d < 365*24*time.Hour:
+		s = fmt.Sprintf("%dd", d/(24*time.Hour))
+	default:
+		s = fmt.Sprintf("%dy", d/(365*24*time.Hour))
+	}
+	if suffix == "" {
+		return "in " + s
+	}
+	return s + suffix
+}
+
+func renderTemplate(w io.Writer, v interface{}, opts Options) error {
+	if opts.Template == "" {
+		return errors.New("no template given")
+	}
+	tmpl, err := template.New("format").Funcs(template.FuncMap{
+		"ago": func(t interface{}) string {
+			return timeCell(reflect.ValueOf(t), opts.Now)
+		},
+		"json": func(v interface{}) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+	}).Parse(opts.Template)
+	if err != nil {
+		return err
+	}
+	for _, item := range items(v) {
+		if err := tmpl.Execute(w, item.Interface()); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, "\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func renderTable(w io.Writer, v interface{}, opts Options) error {
+	if v == nil {
+		return nil
+	}
+	list := items(v)
+	t := elemType(reflect.TypeOf(v))
+	if t.Kind() != reflect.Struct {
+		for _, item := range list {
+			if _, err := fmt.Fprintln(w, cell(item, opts.Now)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	columns := opts.Columns
+	if len(columns) == 0 {
+		columns = DefaultColumns[t]
+	}
+	if len(columns) == 0 {
+		columns = simpleFields(t)
+	}
+	for _, c := range columns {
+		if !hasField(t, c) {
+			return fmt.Errorf("unknown column %q for %s", c, t.Name())
+		}
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	header := make([]string, len(columns))
+	for i, c := range columns {
+		header[i] = strings.ToUpper(c)
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, item := range list {
+		item = reflect.Indirect(item)
+		row := make([]string, len(columns))
+		for i, c := range columns {
+			row[i] = cell(field(item, c), opts.Now)
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// items returns the elements of v if it is a slice, or v itself
+func items(v interface{}) []reflect.Value {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		return nil
+	}
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() && rv.Elem().Kind() == reflect.Slice {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Slice {
+		return []reflect.Value{rv}
+	}
+	list := make([]reflect.Value, rv.Len())
+	for i := range list {
+		list[i] = rv.Index(i)
+	}
+	return list
+}
+
+// elemType strips slices and pointers from t
+func elemType(t reflect.Type) reflect.Type {
+	for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice) {
+		t = t.Elem()
+	}
+	return t
+}
+
+// hasField returns whether the dotted field path exists on the struct type t
+func hasField(t reflect.Type, path string) bool {
+	for _, name := range strings.Split(path, ".") {
+		t = elemType(t)
+		if t.Kind() != reflect.Struct {
+			return false
+		}
+		f, ok := t.FieldByName(name)
+		if !ok {
+			return false
+		}
+		t = f.Type
+	}
+	return true
+}
+
+// field returns the value at the dotted field path of v, or an invalid value
+// when a pointer along the path is nil
+func field(v reflect.Value, path string) reflect.Value {
+	for _, name := range strings.Split(path, ".") {
+		v = reflect.Indirect(v)
+		if !v.IsValid() {
+			return v
+		}
+		v = v.FieldByName(name)
+	}
+	return v
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// isTime returns whether t is time.Time or a struct only embedding it, like
+// now.Timestamp
+func isTime(t reflect.Type) bool {
+	if t == timeType {
+		return true
+	}
+	return t.Kind() == reflect.Struct && t.NumField() == 1 &&
+		t.Field(0).Anonymous && t.Field(0).Type == timeType
+}
+
+// simpleFields returns the exported fields of t rendered as a single cell
+func simpleFields(t reflect.Type) []string {
+	var fields []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		simple := true
+		switch ft.Kind() {
+		case reflect.Struct:
+			simple = isTime(ft)
+		case reflect.Slice:
+			simple = ft.Elem().Kind() == reflect.String
+		case reflect.Map, reflect.Interface:
+			simple = false
+		}
+		if simple {
+			fields = append(fields, f.Name)
+		}
+	}
+	return fields
+}
+
+func cell(v reflect.Value, ref time.Time) string {
+	if !v.IsValid() {
+		return "-"
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "-"
+		}
+		if isTime(v.Elem().Type()) {
+			return timeCell(v, ref)
+		}
+		v = v.Elem()
+	}
+	switch {
+	case isTime(v.Type()):
+		return timeCell(v, ref)
+	case v.Kind() == reflect.Slice:
+		parts := make([]string, v.Len())
+		for i := range parts {
+			parts[i] = cell(v.Index(i), ref)
+		}
+		return strings.Join(parts, ",")
+	case v.Kind() == reflect.Map:
+		var parts []string
+		for _, k := range v.MapKeys() {
+			parts = append(parts, fmt.Sprintf("%v=%s", k.Interface(), cell(v.MapIndex(k), ref)))
+		}
+		sort.Strings(parts)
+		return strings.Join(parts, ",")
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
+
+func timeCell(v reflect.Value, ref time.Time) string {
+	if !v.IsValid() {
+		return "-"
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "-"
+		}
+		v = v.Elem()
+	}
+	if v.Type() != timeType {
+		v = v.Field(0)
+	}
+	t, ok := v.Interface().(time.Time)
+	if !ok || t.IsZero() {
+		return "-"
+	}
+	return Ago(t, ref)
+}
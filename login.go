@@ -0,0 +1,114 @@
+package now
+
+import (
+	"context"
+	"net/url"
+	"time"
+)
+
+const (
+	registrationEndpoint       = "/now/registration"
+	registrationVerifyEndpoint = "/now/registration/verify"
+
+	defaultLoginPollInterval = 3 * time.Second
+)
+
+// LoginFlow drives the interactive steps of an email login
+type LoginFlow interface {
+	// SecurityCode receives the code included in the verification email so
+	// it can be shown to the user for comparison
+	SecurityCode(email, code string)
+	// Wait is called before each verification check and blocks until the
+	// next one should happen. Returning an error aborts the login.
+	Wait(ctx context.Context, attempt int) error
+}
+
+// PollingLoginFlow is a LoginFlow checking the verification at a fixed
+// interval, three seconds unless Interval is positive, and handing the
+// security code to OnCode
+type PollingLoginFlow struct {
+	Interval time.Duration
+	OnCode   func(email, code string)
+}
+
+// SecurityCode implements the LoginFlow interface
+func (f PollingLoginFlow) SecurityCode(email, code string) {
+	if f.OnCode != nil {
+		f.OnCode(email, code)
+	}
+}
+
+// Wait implements the LoginFlow interface
+func (f PollingLoginFlow) Wait(ctx context.Context, attempt int) error {
+	if attempt == 0 {
+		return nil
+	}
+	interval := f.Interval
+	if interval <= 0 {
+		interval = defaultLoginPollInterval
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(interval):
+		return nil
+	}
+}
+
+// Login obtains a token named tokenName for the given email against the
+// default API. See Client.Login.
+func Login(ctx context.Context, email, tokenName string, flow LoginFlow) (string, ClientError) {
+	return New("").client.Login(ctx, email, tokenName, flow)
+}
+
+// Login requests a verification email for the given email and returns the
+// secret of a new token named tokenName once the user has confirmed it. The
+// client's secret is not used and is not updated.
+func (c Client) Login(ctx context.Context, email, tokenName string, flow LoginFlow) (string, ClientError) {
+	if flow == nil {
+		return "", NewError("A login flow is required")
+	}
+	reg := registrationResponse{}
+	err := c.NewRequestContext(ctx, "POST", registrationEndpoint, RegistrationParams{
+		Email:     email,
+		TokenName: tokenName,
+	}, &reg, nil)
+	if err != nil {
+		return "", err
+	}
+	flow.SecurityCode(email, reg.SecurityCode)
+
+	q := url.Values{}
+	q.Set("email", email)
+	q.Set("token", reg.Token)
+	path := registrationVerifyEndpoint + "?" + q.Encode()
+	for attempt := 0; ; attempt++ {
+		if wErr := flow.Wait(ctx, attempt); wErr != nil {
+			return "", NewError(wErr.Error())
+		}
+
+		v := registrationVerifyResponse{}
+		err := c.NewRequestContext(ctx, "GET", path, nil, &v, nil)
+		if err == nil {
+			return v.Token, nil
+		}
+		if err.Code() != "email_not_verified" {
+			return "", err
+		}
+	}
+}
+
+// RegistrationParams contains all fields to request a login
+type RegistrationParams struct {
+	Email     string `json:"email"`
+	TokenName string `json:"tokenName"`
+}
+
+type registrationResponse struct {
+	Token        string `json:"token"`
+	SecurityCode string `json:"securityCode"`
+}
+
+type registrationVerifyResponse struct {
+	Token string `json:"token"`
+}
@@ -14,7 +14,10 @@ type Now struct {
 	Deployments *DeploymentsClient
 	Domains     *DomainsClient
 	Plans       *PlansClient
+	Secrets     *SecretsClient
 	Teams       *TeamsClient
+	User        *UserClient
+	Webhooks    *WebhooksClient
 }
 
 // SetTeamID updates the client's global team_id value
@@ -22,6 +25,12 @@ func (n Now) SetTeamID(teamID string) {
 	n.client.teamID = teamID
 }
 
+// SetHTTPClient overrides the HTTP client used for every request, e.g. to
+// install a custom http.RoundTripper
+func (n Now) SetHTTPClient(h *http.Client) {
+	n.client.SetHTTPClient(h)
+}
+
 // New returns an authenticated Now api client
 func New(secret string) *Now {
 	n := Now{
@@ -34,9 +43,12 @@ func New(secret string) *Now {
 		},
 	}
 	n.Certs = &CertsClient{client: n.client}
-	n.Deployments = &DeploymentsClient{client: n.client}
+	n.Deployments = &DeploymentsClient{client: n.client, history: newAliasHistory()}
 	n.Domains = &DomainsClient{client: n.client}
 	n.Plans = &PlansClient{client: n.client}
+	n.Secrets = &SecretsClient{client: n.client}
 	n.Teams = &TeamsClient{client: n.client}
+	n.User = &UserClient{client: n.client}
+	n.Webhooks = &WebhooksClient{client: n.client}
 	return &n
 }
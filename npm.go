@@ -0,0 +1,134 @@
+package now
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// npmIgnorePaths are excluded from every npm package on top of the defaults
+var npmIgnorePaths = []string{
+	".npmrc",
+	"package-lock.json",
+	"*.orig",
+	"._*",
+	".hg",
+}
+
+// npmAlwaysIncluded matches the root files npm packs regardless of the files
+// whitelist or ignore rules
+var npmAlwaysIncluded = regexp.MustCompile(`(?i)^(readme|license|licence|changes|changelog|history)(\..*)?$`)
+
+// PackageJSON contains the package.json fields used when selecting files
+type PackageJSON struct {
+	Name  string   `json:"name"`
+	Main  string   `json:"main"`
+	Files []string `json:"files"`
+}
+
+// ReadPackageJSON reads the package.json file of the given directory
+func ReadPackageJSON(dir string) (*PackageJSON, error) {
+	b, err := ioutil.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return nil, err
+	}
+	pkg := &PackageJSON{}
+	if err := json.Unmarshal(b, pkg); err != nil {
+		return nil, err
+	}
+	return pkg, nil
+}
+
+// NpmFiles returns an array of paths for a given npm package, following the
+// same rules as npm pack: the package.json files whitelist when present,
+// where "!" entries exclude files, otherwise .npmignore or, failing that,
+// .gitignore. package.json, README,
+// LICENSE, CHANGELOG and the main file are always included.
+func NpmFiles(dir string) (*[]string, error) {
+	pkg, err := ReadPackageJSON(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if pkg == nil {
+		pkg = &PackageJSON{}
+	}
+
+	s := NewFileSelector()
+	s.Exclude = append(s.Exclude, npmIgnorePaths...)
+	switch {
+	case len(pkg.Files) > 0:
+		for _, f := range pkg.Files {
+			if strings.HasPrefix(f, "!") {
+				s.Exclude = append(s.Exclude, npmFilesPattern(f[1:]))
+				continue
+			}
+			s.Include = append(s.Include, npmFilesPattern(f))
+		}
+	case fileExists(filepath.Join(dir, ".npmignore")):
+		s.IgnoreFiles = []string{".npmignore"}
+	default:
+		s.IgnoreFiles = []string{".gitignore"}
+	}
+
+	files, err := s.Select(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	always, err := npmAlwaysIncludedFiles(dir, pkg)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(files))
+	for _, f := range files {
+		seen[f] = true
+	}
+	for _, f := range always {
+		if !seen[f] {
+			files = append(files, f)
+		}
+	}
+	return &files, nil
+}
+
+func npmAlwaysIncludedFiles(dir string, pkg *PackageJSON) ([]string, error) {
+	var files []string
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		if e.Name() == "package.json" || npmAlwaysIncluded.MatchString(e.Name()) {
+			files = append(files, filepath.Join(dir, e.Name()))
+		}
+	}
+
+	if pkg.Main != "" {
+		main := filepath.Join(dir, filepath.FromSlash(pkg.Main))
+		if !fileExists(main) && fileExists(main+".js") {
+			main += ".js"
+		}
+		if fileExists(main) {
+			files = append(files, main)
+		}
+	}
+	return files, nil
+}
+
+// npmFilesPattern anchors an entry of the package.json files list to the
+// package root
+func npmFilesPattern(f string) string {
+	return "/" + strings.TrimLeft(path.Clean(f), "/")
+}
+
+func fileExists(p string) bool {
+	f, err := os.Stat(p)
+	return err == nil && !f.IsDir()
+}
@@ -2,11 +2,27 @@ package now
 
 // Subscription represents a user's subscription state
 type Subscription struct {
-	ID   string `json:"id"`
-	Plan Plan   `json:"plan"`
-	// TODO: Add remaining subscription fields
+	ID                 string    `json:"id"`
+	Plan               Plan      `json:"plan"`
+	Status             string    `json:"status"`
+	Quantity           int64     `json:"quantity"`
+	CancelAtPeriodEnd  bool      `json:"cancel_at_period_end"`
+	Created            *UnixTime `json:"created,omitempty"`
+	CurrentPeriodStart *UnixTime `json:"current_period_start,omitempty"`
+	CurrentPeriodEnd   *UnixTime `json:"current_period_end,omitempty"`
+	TrialStart         *UnixTime `json:"trial_start,omitempty"`
+	TrialEnd           *UnixTime `json:"trial_end,omitempty"`
 }
 
+// Subscription statuses
+const (
+	SubscriptionTrialing = "trialing"
+	SubscriptionActive   = "active"
+	SubscriptionPastDue  = "past_due"
+	SubscriptionCanceled = "canceled"
+	SubscriptionUnpaid   = "unpaid"
+)
+
 // Plan contains all fields relevant to a plan object
 type Plan struct {
 	ID            string `json:"id"`
@@ -16,3 +32,22 @@ type Plan struct {
 	Interval      string `json:"interval"`
 	IntervalCount int64  `json:"interval_count"`
 }
+
+// Usage reports the account's consumption against its plan limits
+type Usage struct {
+	Bandwidth   UsageMetric `json:"bandwidth"`
+	Instances   UsageMetric `json:"instances"`
+	Deployments UsageMetric `json:"deployments"`
+}
+
+// UsageMetric is the amount used of a resource and the plan's limit for it,
+// a limit of zero meaning unlimited
+type UsageMetric struct {
+	Used  int64 `json:"used"`
+	Limit int64 `json:"limit"`
+}
+
+// Exceeded returns whether the usage is over the limit
+func (m UsageMetric) Exceeded() bool {
+	return m.Limit > 0 && m.Used > m.Limit
+}
@@ -18,4 +18,25 @@ type planResponse struct {
 	Subscription Subscription `json:"subscription,omitempty"`
 }
 
-// TODO: implement Set
+// Set changes the authenticated user's plan. Since this may incur charges,
+// confirm must be true or no request is made.
+func (c PlansClient) Set(planID string, confirm bool) (Subscription, ClientError) {
+	r := planResponse{}
+	if !confirm {
+		return r.Subscription, NewError("Plan change to " + planID + " was not confirmed")
+	}
+	err := c.client.NewRequest("PUT", planEndpoint, PlanParams{ID: planID}, &r, nil)
+	return r.Subscription, err
+}
+
+// PlanParams contains all fields for a plan change
+type PlanParams struct {
+	ID string `json:"plan"`
+}
+
+// Usage returns the authenticated user's usage against the plan limits
+func (c PlansClient) Usage() (Usage, ClientError) {
+	u := Usage{}
+	err := c.client.NewRequest("GET", planEndpoint+"/usage", nil, &u, nil)
+	return u, err
+}
@@ -0,0 +1,90 @@
+package now
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// PrunePolicy describes which deployments Prune removes. A deployment is
+// removed when it matches any rule; aliased deployments and deployments
+// without a creation date are always kept.
+type PrunePolicy struct {
+	// Before removes deployments created before the given time
+	Before time.Time
+	// KeepPerName removes all but the newest KeepPerName deployments sharing
+	// a name; zero disables the rule
+	KeepPerName int
+	// DryRun returns the plan without deleting anything
+	DryRun bool
+}
+
+// PruneAction is a deployment selected for removal and why
+type PruneAction struct {
+	Deployment Deployment
+	Reason     string
+}
+
+// Prune deletes the deployments matching policy and returns the actions
+// taken, or the actions that would be taken in dry-run mode. When a delete
+// fails the actions completed so far are returned with the error.
+func (c DeploymentsClient) Prune(policy PrunePolicy) ([]PruneAction, ClientError) {
+	deployments, err := c.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []Deployment
+	for _, d := range deployments {
+		if d.Created != nil {
+			candidates = append(candidates, d)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Created.After(candidates[j].Created.Time)
+	})
+
+	var plan []PruneAction
+	seen := make(map[string]int)
+	for _, d := range candidates {
+		seen[d.Name]++
+		var reason string
+		switch {
+		case !policy.Before.IsZero() && d.Created.Before(policy.Before):
+			reason = fmt.Sprintf("created before %s", policy.Before.Format(time.RFC3339))
+		case policy.KeepPerName > 0 && seen[d.Name] > policy.KeepPerName:
+			reason = fmt.Sprintf("more than %d deployments named %q", policy.KeepPerName, d.Name)
+		default:
+			continue
+		}
+
+		aliased, err := c.isAliased(d)
+		if err != nil {
+			return nil, err
+		}
+		if !aliased {
+			plan = append(plan, PruneAction{Deployment: d, Reason: reason})
+		}
+	}
+
+	if policy.DryRun {
+		return plan, nil
+	}
+	for i, a := range plan {
+		if err := c.Delete(a.Deployment.UID); err != nil {
+			return plan[:i], err
+		}
+	}
+	return plan, nil
+}
+
+func (c DeploymentsClient) isAliased(d Deployment) (bool, ClientError) {
+	if len(d.Aliases) > 0 {
+		return true, nil
+	}
+	aliases, err := c.ListAliases(d.UID)
+	if err != nil {
+		return false, err
+	}
+	return len(aliases) > 0, nil
+}
@@ -0,0 +1,157 @@
+// Package reconcile compares a declarative manifest of a Now account with
+// its current state, producing a plan of changes which can then be applied.
+package reconcile
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Manifest describes the desired state of a Now account
+type Manifest struct {
+	Domains []Domain    `json:"domains,omitempty" yaml:"domains,omitempty"`
+	DNS     []DNSRecord `json:"dns,omitempty" yaml:"dns,omitempty"`
+	Certs   []Cert      `json:"certs,omitempty" yaml:"certs,omitempty"`
+	Secrets []Secret    `json:"secrets,omitempty" yaml:"secrets,omitempty"`
+	Aliases []Alias     `json:"aliases,omitempty" yaml:"aliases,omitempty"`
+	Scale   []Scale     `json:"scale,omitempty" yaml:"scale,omitempty"`
+}
+
+// Domain is a domain which must be registered with the account
+type Domain struct {
+	Name     string `json:"name" yaml:"name"`
+	External bool   `json:"external,omitempty" yaml:"external,omitempty"`
+}
+
+// DNSRecord is a record which must exist for a domain
+type DNSRecord struct {
+	Domain string `json:"domain" yaml:"domain"`
+	Name   string `json:"name" yaml:"name"`
+	Type   string `json:"type" yaml:"type"`
+	Value  string `json:"value" yaml:"value"`
+}
+
+// Cert is a certificate which must cover all the given domains
+type Cert struct {
+	Domains []string `json:"domains" yaml:"domains"`
+}
+
+// Secret is a secret which must exist. Its value is read from FromEnv when
+// Value is empty. Values of existing secrets cannot be read back, so only
+// missing secrets are created.
+type Secret struct {
+	Name    string `json:"name" yaml:"name"`
+	Value   string `json:"value,omitempty" yaml:"value,omitempty"`
+	FromEnv string `json:"fromEnv,omitempty" yaml:"fromEnv,omitempty"`
+}
+
+// DeploymentRef identifies a deployment either by ID or, when ID is empty,
+// as the newest READY deployment whose meta labels match Meta
+type DeploymentRef struct {
+	ID   string            `json:"deployment,omitempty" yaml:"deployment,omitempty"`
+	Meta map[string]string `json:"meta,omitempty" yaml:"meta,omitempty"`
+}
+
+// Alias is an alias which must point to the referenced deployment
+type Alias struct {
+	Alias         string `json:"alias" yaml:"alias"`
+	DeploymentRef `yaml:",inline"`
+}
+
+// Scale is the instance range the referenced deployment must be scaled to
+type Scale struct {
+	DeploymentRef `yaml:",inline"`
+	Min           int `json:"min" yaml:"min"`
+	Max           int `json:"max" yaml:"max"`
+}
+
+// Load reads a YAML or JSON manifest from the file at path
+func Load(path string) (*Manifest, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(b)
+}
+
+// Parse decodes and validates a YAML or JSON manifest
+func Parse(b []byte) (*Manifest, error) {
+	m := &Manifest{}
+	if err := yaml.UnmarshalStrict(b, m); err != nil {
+		return nil, err
+	}
+	return m, m.Validate()
+}
+
+// Validate checks every entry of the manifest has its required fields
+func (m *Manifest) Validate() error {
+	for i, d := range m.Domains {
+		if d.Name == "" {
+			return fmt.Errorf("domains[%d]: name is required", i)
+		}
+	}
+	for i, r := range m.DNS {
+		if r.Domain == "" || r.Type == "" || r.Value == "" {
+			return fmt.Errorf("dns[%d]: domain, type and value are required", i)
+		}
+	}
+	for i, c := range m.Certs {
+		if len(c.Domains) == 0 {
+			return fmt.Errorf("certs[%d]: domains are required", i)
+		}
+	}
+	for i, s := range m.Secrets {
+		if s.Name == "" {
+			return fmt.Errorf("secrets[%d]: name is required", i)
+		}
+		if s.Value == "" && s.FromEnv == "" {
+			return fmt.Errorf("secrets[%d]: value or fromEnv is required", i)
+		}
+	}
+	for i, a := range m.Aliases {
+		if a.Alias == "" {
+			return fmt.Errorf("aliases[%d]: alias is required", i)
+		}
+		if err := a.DeploymentRef.validate(); err != nil {
+			return fmt.Errorf("aliases[%d]: %s", i, err)
+		}
+	}
+	for i, s := range m.Scale {
+		if err := s.DeploymentRef.validate(); err != nil {
+			return fmt.Errorf("scale[%d]: %s", i, err)
+		}
+		if s.Min < 0 || s.Max < s.Min {
+			return fmt.Errorf("scale[%d]: invalid range %d-%d", i, s.Min, s.Max)
+		}
+	}
+	return nil
+}
+
+func (r DeploymentRef) validate() error {
+	if r.ID == "" && len(r.Meta) == 0 {
+		return errors.New("deployment or meta is required")
+	}
+	return nil
+}
+
+func (r DeploymentRef) describe() string {
+	if r.ID != "" {
+		return r.ID
+	}
+	return fmt.Sprintf("meta %v", r.Meta)
+}
+
+func (s Secret) value() (string, error) {
+	if s.Value != "" {
+		return s.Value, nil
+	}
+	v := os.Getenv(s.FromEnv)
+	if v == "" {
+		return "", fmt.Errorf("secret %s: $%s is not set", s.Name, s.FromEnv)
+	}
+	return v, nil
+}
@@ -0,0 +1,439 @@
+package reconcile
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/manifoldco/go-now"
+)
+
+// Action represents the kind of a Change
+type Action string
+
+// Actions
+const (
+	Create Action = "create"
+	Update Action = "update"
+	Delete Action = "delete"
+)
+
+var actionSymbols = map[Action]string{
+	Create: "+",
+	Update: "~",
+	Delete: "-",
+}
+
+// Change is a single operation needed to reach the desired state
+type Change struct {
+	Action Action
+	Kind   string
+	Name   string
+	Detail string
+
+	apply func(n *now.Now) error
+}
+
+// String implements the fmt.Stringer interface
+func (c Change) String() string {
+	s := fmt.Sprintf("%s %s %s", actionSymbols[c.Action], c.Kind, c.Name)
+	if c.Detail != "" {
+		s += " (" + c.Detail + ")"
+	}
+	return s
+}
+
+// Plan is the ordered list of changes turning the current state into the
+// one described by a manifest
+type Plan struct {
+	Changes []Change
+}
+
+// Empty returns whether the account already matches the manifest
+func (p Plan) Empty() bool {
+	return len(p.Changes) == 0
+}
+
+// Write prints the plan, one change per line
+func (p Plan) Write(w io.Writer) error {
+	if p.Empty() {
+		_, err := fmt.Fprintln(w, "No changes.")
+		return err
+	}
+	for _, c := range p.Changes {
+		if _, err := fmt.Fprintln(w, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Apply performs the changes in order, stopping at the first failure. The
+// number of changes applied is returned along with the error.
+func (p Plan) Apply(n *now.Now) (int, error) {
+	for i, c := range p.Changes {
+		if err := c.apply(n); err != nil {
+			return i, fmt.Errorf("%s: %s", c, err)
+		}
+	}
+	return len(p.Changes), nil
+}
+
+// Options controls how a plan is computed
+type Options struct {
+	// Prune deletes domains, DNS records of managed domains, certs and
+	// secrets which are not in the manifest. A kind is only pruned when the
+	// manifest has a section for it, and certs covering a managed alias are
+	// always kept.
+	Prune bool
+}
+
+// Diff compares the manifest with the account's current state and returns
+// the plan reconciling them
+func Diff(n *now.Now, m *Manifest, opts Options) (*Plan, error) {
+	d := differ{now: n, manifest: m, opts: opts, plan: &Plan{}}
+	steps := []func() error{
+		d.domains,
+		d.dns,
+		d.certs,
+		d.secrets,
+		d.aliases,
+		d.scale,
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return nil, err
+		}
+	}
+	// Deletions run last so nothing still referenced goes away first
+	sort.SliceStable(d.plan.Changes, func(i, j int) bool {
+		return d.plan.Changes[i].Action != Delete && d.plan.Changes[j].Action == Delete
+	})
+	return d.plan, nil
+}
+
+type differ struct {
+	now      *now.Now
+	manifest *Manifest
+	opts     Options
+	plan     *Plan
+}
+
+func (d *differ) add(c Change) {
+	d.plan.Changes = append(d.plan.Changes, c)
+}
+
+func (d *differ) domains() error {
+	current, err := d.now.Domains.List()
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]bool, len(current))
+	for _, c := range current {
+		existing[c.Name] = true
+	}
+	wanted := make(map[string]bool, len(d.manifest.Domains))
+	for _, w := range d.manifest.Domains {
+		w := w
+		wanted[w.Name] = true
+		if existing[w.Name] {
+			continue
+		}
+		d.add(Change{Action: Create, Kind: "domain", Name: w.Name, apply: func(n *now.Now) error {
+			_, err := n.Domains.New(w.Name, w.External)
+			return err
+		}})
+	}
+	if !d.opts.Prune || d.manifest.Domains == nil {
+		return nil
+	}
+	for _, c := range current {
+		c := c
+		if wanted[c.Name] {
+			continue
+		}
+		d.add(Change{Action: Delete, Kind: "domain", Name: c.Name, apply: func(n *now.Now) error {
+			return n.Domains.Delete(c.Name)
+		}})
+	}
+	return nil
+}
+
+// recordKey identifies a DNS record by its name, type and value, as several
+// records such as MX or TXT ones may share a name and type
+func recordKey(name, typ, value string) string {
+	return name + " " + strings.ToUpper(typ) + " " + value
+}
+
+func (d *differ) dns() error {
+	byDomain := make(map[string][]DNSRecord)
+	var domains []string
+	for _, r := range d.manifest.DNS {
+		if _, ok := byDomain[r.Domain]; !ok {
+			domains = append(domains, r.Domain)
+		}
+		byDomain[r.Domain] = append(byDomain[r.Domain], r)
+	}
+
+	for _, domain := range domains {
+		domain := domain
+		current, err := d.now.Domains.Records(domain)
+		if err != nil && err.StatusCode() != 404 {
+			return err
+		}
+		existing := make(map[string]bool, len(current))
+		sameType := make(map[string][]now.DNSRecord)
+		for _, c := range current {
+			existing[recordKey(c.Name, c.Type, c.Value)] = true
+			t := recordKey(c.Name, c.Type, "")
+			sameType[t] = append(sameType[t], c)
+		}
+		wantedType := make(map[string]int)
+		for _, w := range byDomain[domain] {
+			wantedType[recordKey(w.Name, w.Type, "")]++
+		}
+
+		wanted := make(map[string]bool)
+		for _, w := range byDomain[domain] {
+			key := recordKey(w.Name, w.Type, w.Value)
+			wanted[key] = true
+			if existing[key] {
+				continue
+			}
+			params := now.DNSRecordParams{Name: w.Name, Type: strings.ToUpper(w.Type), Value: w.Value}
+			name := strings.TrimPrefix(w.Name+"."+domain, ".")
+
+			// The value of the only record with this name and type changed
+			t := recordKey(w.Name, w.Type, "")
+			if others := sameType[t]; len(others) == 1 && wantedType[t] == 1 {
+				c := others[0]
+				wanted[recordKey(c.Name, c.Type, c.Value)] = true
+				d.add(Change{Action: Update, Kind: "dns", Name: name, Detail: fmt.Sprintf("%s %s -> %s", params.Type, c.Value, w.Value), apply: func(n *now.Now) error {
+					if _, err := n.Domains.NewRecord(domain, params); err != nil {
+						return err
+					}
+					return n.Domains.DeleteRecord(domain, c.ID)
+				}})
+				continue
+			}
+			d.add(Change{Action: Create, Kind: "dns", Name: name, Detail: params.Type + " " + w.Value, apply: func(n *now.Now) error {
+				_, err := n.Domains.NewRecord(domain, params)
+				return err
+			}})
+		}
+
+		if !d.opts.Prune {
+			continue
+		}
+		for _, c := range current {
+			c := c
+			if wanted[recordKey(c.Name, c.Type, c.Value)] {
+				continue
+			}
+			name := strings.TrimPrefix(c.Name+"."+domain, ".")
+			d.add(Change{Action: Delete, Kind: "dns", Name: name, Detail: c.Type + " " + c.Value, apply: func(n *now.Now) error {
+				return n.Domains.DeleteRecord(domain, c.ID)
+			}})
+		}
+	}
+	return nil
+}
+
+func covers(c *now.Cert, domains []string) bool {
+	names := make(map[string]bool, len(c.CommonNames))
+	for _, cn := range c.CommonNames {
+		names[cn] = true
+	}
+	for _, d := range domains {
+		if !names[d] {
+			return false
+		}
+	}
+	return true
+}
+
+// coversAlias returns whether c is valid for one of the manifest's aliases
+func (d *differ) coversAlias(c *now.Cert) bool {
+	for _, a := range d.manifest.Aliases {
+		for _, cn := range c.CommonNames {
+			if cn == a.Alias || wildcardCovers(cn, a.Alias) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// wildcardCovers returns whether the wildcard name cn, such as
+// "*.example.com", matches host
+func wildcardCovers(cn, host string) bool {
+	if !strings.HasPrefix(cn, "*.") || !strings.HasSuffix(host, cn[1:]) {
+		return false
+	}
+	label := strings.TrimSuffix(host, cn[1:])
+	return label != "" && !strings.Contains(label, ".")
+}
+
+func (d *differ) certs() error {
+	current, err := d.now.Certs.List()
+	if err != nil {
+		return err
+	}
+	used := make(map[string]bool)
+	for _, w := range d.manifest.Certs {
+		w := w
+		found := false
+		for _, c := range current {
+			if covers(c, w.Domains) {
+				used[c.UID] = true
+				found = true
+			}
+		}
+		if found {
+			continue
+		}
+		d.add(Change{Action: Create, Kind: "cert", Name: strings.Join(w.Domains, ","), apply: func(n *now.Now) error {
+			_, err := n.Certs.New(w.Domains)
+			return err
+		}})
+	}
+	if !d.opts.Prune || d.manifest.Certs == nil {
+		return nil
+	}
+	for _, c := range current {
+		c := c
+		if used[c.UID] || len(c.CommonNames) == 0 || d.coversAlias(c) {
+			continue
+		}
+		d.add(Change{Action: Delete, Kind: "cert", Name: strings.Join(c.CommonNames, ","), apply: func(n *now.Now) error {
+			return n.Certs.Delete(c.CommonNames[0])
+		}})
+	}
+	return nil
+}
+
+func (d *differ) secrets() error {
+	current, err := d.now.Secrets.List()
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]bool, len(current))
+	for _, c := range current {
+		existing[c.Name] = true
+	}
+	wanted := make(map[string]bool, len(d.manifest.Secrets))
+	for _, w := range d.manifest.Secrets {
+		wanted[w.Name] = true
+		if existing[w.Name] {
+			continue
+		}
+		name := w.Name
+		value, err := w.value()
+		if err != nil {
+			return err
+		}
+		d.add(Change{Action: Create, Kind: "secret", Name: name, apply: func(n *now.Now) error {
+			_, err := n.Secrets.New(name, value)
+			return err
+		}})
+	}
+	if !d.opts.Prune || d.manifest.Secrets == nil {
+		return nil
+	}
+	for _, c := range current {
+		c := c
+		if wanted[c.Name] {
+			continue
+		}
+		d.add(Change{Action: Delete, Kind: "secret", Name: c.Name, apply: func(n *now.Now) error {
+			return n.Secrets.Delete(c.UID)
+		}})
+	}
+	return nil
+}
+
+func (d *differ) aliases() error {
+	if len(d.manifest.Aliases) == 0 {
+		return nil
+	}
+	current, err := d.now.Deployments.AllAliases()
+	if err != nil {
+		return err
+	}
+	targets := make(map[string]string, len(current))
+	for _, c := range current {
+		targets[c.Alias] = c.DeploymentID
+	}
+	for _, w := range d.manifest.Aliases {
+		alias := w.Alias
+		id, err := d.resolve(w.DeploymentRef)
+		if err != nil {
+			return fmt.Errorf("alias %s: %s", alias, err)
+		}
+		target, ok := targets[alias]
+		if ok && target == id {
+			continue
+		}
+		c := Change{Action: Create, Kind: "alias", Name: alias, Detail: "-> " + id, apply: func(n *now.Now) error {
+			_, err := n.Deployments.Alias(id, alias)
+			return err
+		}}
+		if ok {
+			c.Action = Update
+			c.Detail = target + " -> " + id
+		}
+		d.add(c)
+	}
+	return nil
+}
+
+func (d *differ) scale() error {
+	for _, w := range d.manifest.Scale {
+		w := w
+		id, err := d.resolve(w.DeploymentRef)
+		if err != nil {
+			return fmt.Errorf("scale %s: %s", w.DeploymentRef.describe(), err)
+		}
+		dep, cErr := d.now.Deployments.Get(id)
+		if cErr != nil {
+			return cErr
+		}
+		if s := dep.Scale; s != nil && s.Min == w.Min && s.Max == w.Max {
+			continue
+		}
+		d.add(Change{Action: Update, Kind: "scale", Name: id, Detail: fmt.Sprintf("%d-%d", w.Min, w.Max), apply: func(n *now.Now) error {
+			_, err := n.Deployments.Scale(id, w.Min, w.Max)
+			return err
+		}})
+	}
+	return nil
+}
+
+// resolve returns the ID of the referenced deployment
+func (d *differ) resolve(ref DeploymentRef) (string, error) {
+	if ref.ID != "" {
+		return ref.ID, nil
+	}
+	var selectors []now.MetaSelector
+	for k, v := range ref.Meta {
+		selectors = append(selectors, now.MetaEquals(k, v))
+	}
+	deployments, err := d.now.Deployments.List(selectors...)
+	if err != nil {
+		return "", err
+	}
+	var newest *now.Deployment
+	for i, dep := range deployments {
+		if dep.State != now.StateReady || dep.Created == nil {
+			continue
+		}
+		if newest == nil || dep.Created.After(newest.Created.Time) {
+			newest = &deployments[i]
+		}
+	}
+	if newest == nil {
+		return "", fmt.Errorf("no READY deployment matches %s", ref.describe())
+	}
+	return newest.UID, nil
+}
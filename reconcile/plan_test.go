@@ -0,0 +1,251 @@
+package reconcile
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/manifoldco/go-now"
+)
+
+// fakeAPI serves a fixed account state and records the mutating calls made
+type fakeAPI struct {
+	domains []now.Domain
+	records []now.DNSRecord
+	certs   []*now.Cert
+	secrets []now.Secret
+	aliases []now.Alias
+
+	failRecordCreate bool
+
+	mu    sync.Mutex
+	calls []string
+}
+
+func (f *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		f.mu.Lock()
+		f.calls = append(f.calls, r.Method+" "+r.URL.Path)
+		f.mu.Unlock()
+		if r.Method == "POST" && strings.HasSuffix(r.URL.Path, "/records") && f.failRecordCreate {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":{"code":"invalid_record","message":"invalid record"}}`))
+			return
+		}
+		w.Write([]byte(`{}`))
+		return
+	}
+
+	var v interface{}
+	switch r.URL.Path {
+	case "/domains":
+		v = map[string]interface{}{"domains": f.domains}
+	case "/domains/example.com/records":
+		v = map[string]interface{}{"records": f.records}
+	case "/now/certs":
+		v = map[string]interface{}{"certificates": f.certs}
+	case "/now/secrets":
+		v = map[string]interface{}{"secrets": f.secrets}
+	case "/now/aliases":
+		v = map[string]interface{}{"aliases": f.aliases}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":{"code":"not_found","message":"not found"}}`))
+		return
+	}
+	json.NewEncoder(w).Encode(v)
+}
+
+// rewrite sends every request to the fake API
+type rewrite struct {
+	target *url.URL
+}
+
+func (rw rewrite) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.URL.Scheme = rw.target.Scheme
+	req.URL.Host = rw.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func client(t *testing.T, f *fakeAPI) (*now.Now, func()) {
+	srv := httptest.NewServer(f)
+	target, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := now.New("token")
+	n.SetHTTPClient(&http.Client{Transport: rewrite{target}})
+	return n, srv.Close
+}
+
+func changes(p *Plan) []string {
+	var s []string
+	for _, c := range p.Changes {
+		s = append(s, c.String())
+	}
+	sort.Strings(s)
+	return s
+}
+
+func dnsAPI() *fakeAPI {
+	return &fakeAPI{
+		domains: []now.Domain{{Name: "example.com"}},
+		records: []now.DNSRecord{
+			{ID: "mx1", Name: "", Type: "MX", Value: "10 mx1.example.com"},
+			{ID: "mx2", Name: "", Type: "MX", Value: "20 mx2.example.com"},
+			{ID: "www", Name: "www", Type: "CNAME", Value: "old.now.sh"},
+			{ID: "txt", Name: "", Type: "TXT", Value: "stale"},
+		},
+	}
+}
+
+var dnsManifest = &Manifest{
+	DNS: []DNSRecord{
+		{Domain: "example.com", Type: "MX", Value: "10 mx1.example.com"},
+		{Domain: "example.com", Type: "mx", Value: "30 mx3.example.com"},
+		{Domain: "example.com", Name: "www", Type: "CNAME", Value: "new.now.sh"},
+	},
+}
+
+func TestDiffDNS(t *testing.T) {
+	tests := []struct {
+		name  string
+		prune bool
+		want  []string
+	}{
+		{
+			name: "without prune",
+			want: []string{
+				"+ dns example.com (MX 30 mx3.example.com)",
+				"~ dns www.example.com (CNAME old.now.sh -> new.now.sh)",
+			},
+		},
+		{
+			name:  "with prune",
+			prune: true,
+			want: []string{
+				"+ dns example.com (MX 30 mx3.example.com)",
+				"- dns example.com (MX 20 mx2.example.com)",
+				"- dns example.com (TXT stale)",
+				"~ dns www.example.com (CNAME old.now.sh -> new.now.sh)",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := dnsAPI()
+			n, done := client(t, f)
+			defer done()
+
+			p, err := Diff(n, dnsManifest, Options{Prune: tt.prune})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := changes(p); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("changes %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyDNS(t *testing.T) {
+	f := dnsAPI()
+	n, done := client(t, f)
+	defer done()
+
+	p, err := Diff(n, dnsManifest, Options{Prune: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Apply(n); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"POST /domains/example.com/records",
+		"POST /domains/example.com/records",
+		"DELETE /domains/example.com/records/www",
+		"DELETE /domains/example.com/records/mx2",
+		"DELETE /domains/example.com/records/txt",
+	}
+	if !reflect.DeepEqual(f.calls, want) {
+		t.Fatalf("calls %q, want %q", f.calls, want)
+	}
+}
+
+func TestApplyDNSUpdateKeepsRecordOnFailure(t *testing.T) {
+	f := dnsAPI()
+	f.failRecordCreate = true
+	n, done := client(t, f)
+	defer done()
+
+	m := &Manifest{DNS: []DNSRecord{{Domain: "example.com", Name: "www", Type: "CNAME", Value: "new.now.sh"}}}
+	p, err := Diff(n, m, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	applied, err := p.Apply(n)
+	if err == nil || applied != 0 {
+		t.Fatalf("applied %d changes with error %v, want a failure", applied, err)
+	}
+	for _, c := range f.calls {
+		if strings.HasPrefix(c, "DELETE") {
+			t.Fatalf("record deleted after a failed update: %q", f.calls)
+		}
+	}
+}
+
+func TestDiffPruneScope(t *testing.T) {
+	api := func() *fakeAPI {
+		return &fakeAPI{
+			domains: []now.Domain{{Name: "example.com"}},
+			certs: []*now.Cert{
+				{UID: "c1", CommonNames: []string{"app.example.com"}},
+				{UID: "c2", CommonNames: []string{"*.example.com"}},
+				{UID: "c3", CommonNames: []string{"old.example.com"}},
+			},
+			secrets: []now.Secret{{UID: "s1", Name: "api-key"}},
+			aliases: []now.Alias{{Alias: "app.example.com", DeploymentID: "dpl_1"}},
+		}
+	}
+	aliases := []Alias{{Alias: "app.example.com", DeploymentRef: DeploymentRef{ID: "dpl_1"}}}
+
+	tests := []struct {
+		name     string
+		manifest *Manifest
+		want     []string
+	}{
+		{
+			name:     "only aliases",
+			manifest: &Manifest{Aliases: aliases},
+		},
+		{
+			name:     "empty sections",
+			manifest: &Manifest{Aliases: aliases, Domains: []Domain{}, Certs: []Cert{}, Secrets: []Secret{}},
+			want: []string{
+				"- cert old.example.com",
+				"- domain example.com",
+				"- secret api-key",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, done := client(t, api())
+			defer done()
+
+			p, err := Diff(n, tt.manifest, Options{Prune: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := changes(p); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("changes %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,10 @@
+package now
+
+import "time"
+
+// Secret is the contents of a secret object. The value is never returned.
+type Secret struct {
+	UID     string     `json:"uid"`
+	Name    string     `json:"name"`
+	Created *time.Time `json:"created,omitempty"`
+}
@@ -0,0 +1,44 @@
+package now
+
+import (
+	"fmt"
+)
+
+const secretsEndpoint = "/now/secrets"
+
+// SecretsClient contains the methods for the Secret API
+type SecretsClient struct {
+	client *Client
+}
+
+// New creates a new Secret
+func (c SecretsClient) New(name, value string) (Secret, ClientError) {
+	s := Secret{}
+	err := c.client.NewRequest("POST", secretsEndpoint, SecretParams{
+		Name:  name,
+		Value: value,
+	}, &s, nil)
+	return s, err
+}
+
+// SecretParams contains all fields for secret create
+type SecretParams struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// List retrieves a list of all the secrets under the account
+func (c SecretsClient) List() ([]Secret, ClientError) {
+	s := &secretListResponse{}
+	err := c.client.NewRequest("GET", secretsEndpoint, nil, s, nil)
+	return s.Secrets, err
+}
+
+type secretListResponse struct {
+	Secrets []Secret `json:"secrets"`
+}
+
+// Delete deletes the secret by its name or ID
+func (c SecretsClient) Delete(nameOrID string) ClientError {
+	return c.client.NewRequest("DELETE", fmt.Sprintf("%s/%s", secretsEndpoint, nameOrID), nil, nil, nil)
+}
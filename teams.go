@@ -11,10 +11,19 @@ type Team struct {
 	Created   *time.Time `json:"created,omitempty"`
 }
 
+// TeamRole represents a TeamMember role string
+type TeamRole string
+
+// TeamRoles
+const (
+	RoleOwner  TeamRole = "OWNER"
+	RoleMember TeamRole = "MEMBER"
+)
+
 // TeamMember represents a membership to a team
 type TeamMember struct {
-	UID      string `json:"uid"`
-	Role     string `json:"role"`
-	Email    string `json:"email"`
-	Username string `json:"username"`
+	UID      string   `json:"uid"`
+	Role     TeamRole `json:"role"`
+	Email    string   `json:"email"`
+	Username string   `json:"username"`
 }
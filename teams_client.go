@@ -53,11 +53,32 @@ func (c TeamsClient) Delete(teamID string) ClientError {
 	return c.client.NewRequest("DELETE", fmt.Sprintf("%s/%s", teamsEndpoint, teamID), nil, nil, nil)
 }
 
+// Get retrieves a team by its ID
+func (c TeamsClient) Get(teamID string) (Team, ClientError) {
+	t := Team{}
+	err := c.client.NewRequest("GET", fmt.Sprintf("%s/%s", teamsEndpoint, teamID), nil, &t, nil)
+	return t, err
+}
+
+// Update changes the name or slug of the specified team
+func (c TeamsClient) Update(teamID string, params TeamUpdateParams) (Team, ClientError) {
+	t := Team{}
+	err := c.client.NewRequest("PATCH", fmt.Sprintf("%s/%s", teamsEndpoint, teamID), params, &t, nil)
+	return t, err
+}
+
+// TeamUpdateParams contains possible fields for update
+type TeamUpdateParams struct {
+	Name string `json:"name,omitempty"`
+	Slug string `json:"slug,omitempty"`
+}
+
 // Rename updates the name value for the specified team
 func (c TeamsClient) Rename(teamID, name string) ClientError {
-	return c.client.NewRequest("POST", fmt.Sprintf("%s/%s/members", teamsEndpoint, teamID), &RenameParams{
+	_, err := c.Update(teamID, TeamUpdateParams{
 		Name: name,
-	}, nil, nil)
+	})
+	return err
 }
 
 // RenameParams contains possible fields for rename
@@ -76,3 +97,20 @@ func (c TeamsClient) InviteUser(teamID, email string) ClientError {
 type InviteParams struct {
 	Email string `json:"email"`
 }
+
+// SetMemberRole changes the role of a member of the specified team
+func (c TeamsClient) SetMemberRole(teamID, uid string, role TeamRole) ClientError {
+	return c.client.NewRequest("PATCH", fmt.Sprintf("%s/%s/members/%s", teamsEndpoint, teamID, uid), &MemberRoleParams{
+		Role: role,
+	}, nil, nil)
+}
+
+// MemberRoleParams contains possible fields for a role change
+type MemberRoleParams struct {
+	Role TeamRole `json:"role"`
+}
+
+// RemoveMember removes a member from the specified team
+func (c TeamsClient) RemoveMember(teamID, uid string) ClientError {
+	return c.client.NewRequest("DELETE", fmt.Sprintf("%s/%s/members/%s", teamsEndpoint, teamID, uid), nil, nil, nil)
+}
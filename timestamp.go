@@ -0,0 +1,69 @@
+package now
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// Timestamp is a time sent by the API as milliseconds since the epoch, either
+// as a number or as a numeric string
+type Timestamp struct {
+	time.Time
+}
+
+// UnmarshalJSON implements json.Unmarshaler, also accepting RFC 3339 strings
+func (t *Timestamp) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(b, []byte("null")) {
+		return nil
+	}
+	s := string(b)
+	if len(b) > 1 && b[0] == '"' {
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		if s == "" {
+			return nil
+		}
+	}
+	if ms, err := strconv.ParseInt(s, 10, 64); err == nil {
+		t.Time = time.Unix(0, ms*int64(time.Millisecond))
+		return nil
+	}
+	v, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return err
+	}
+	t.Time = v
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler, writing milliseconds since the epoch
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.FormatInt(t.UnixNano()/int64(time.Millisecond), 10)), nil
+}
+
+// UnixTime is a time sent as seconds since the epoch, as done by the billing
+// API
+type UnixTime struct {
+	time.Time
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (t *UnixTime) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(b, []byte("null")) {
+		return nil
+	}
+	var sec int64
+	if err := json.Unmarshal(b, &sec); err != nil {
+		return err
+	}
+	t.Time = time.Unix(sec, 0)
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler, writing seconds since the epoch
+func (t UnixTime) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.FormatInt(t.Unix(), 10)), nil
+}
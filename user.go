@@ -0,0 +1,34 @@
+package now
+
+import "time"
+
+// User is the contents of the authenticated user object
+type User struct {
+	UID      string       `json:"uid"`
+	Email    string       `json:"email"`
+	Username string       `json:"username"`
+	Name     string       `json:"name,omitempty"`
+	Created  *time.Time   `json:"date,omitempty"`
+	Billing  *UserBilling `json:"billing,omitempty"`
+}
+
+// UserBilling represents the billing state of a user
+type UserBilling struct {
+	Plan        string     `json:"plan"`
+	Period      string     `json:"period,omitempty"`
+	Trial       *time.Time `json:"trial,omitempty"`
+	Cancelation *time.Time `json:"cancelation,omitempty"`
+	Addons      []string   `json:"addons,omitempty"`
+}
+
+// Token is the contents of an API token object. The secret value is only
+// returned on creation.
+type Token struct {
+	ID      string     `json:"id"`
+	Name    string     `json:"name"`
+	Type    string     `json:"type,omitempty"`
+	Origin  string     `json:"origin,omitempty"`
+	Created *time.Time `json:"created,omitempty"`
+	Updated *time.Time `json:"updated,omitempty"`
+	Secret  string     `json:"-"`
+}
@@ -0,0 +1,78 @@
+package now
+
+import (
+	"context"
+	"fmt"
+)
+
+const (
+	userEndpoint   = "/www/user"
+	tokensEndpoint = "/user/tokens"
+)
+
+// UserClient contains the methods for the User API
+type UserClient struct {
+	client *Client
+}
+
+// Me retrieves the user the secret belongs to
+func (c UserClient) Me() (User, ClientError) {
+	u := userResponse{}
+	err := c.client.NewRequest("GET", userEndpoint, nil, &u, nil)
+	return u.User, err
+}
+
+type userResponse struct {
+	User User `json:"user"`
+}
+
+// Authenticated validates the secret against the API. An invalid or revoked
+// secret returns false without error.
+func (c UserClient) Authenticated(ctx context.Context) (bool, ClientError) {
+	if !c.client.Authenticated() {
+		return false, nil
+	}
+	err := c.client.NewRequestContext(ctx, "GET", userEndpoint, nil, nil, nil)
+	if err != nil {
+		if s := err.StatusCode(); s == 401 || s == 403 {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// Tokens retrieves the API tokens of the user
+func (c UserClient) Tokens() ([]Token, ClientError) {
+	t := &tokenListResponse{}
+	err := c.client.NewRequest("GET", tokensEndpoint, nil, t, nil)
+	return t.Tokens, err
+}
+
+type tokenListResponse struct {
+	Tokens []Token `json:"tokens"`
+}
+
+// NewToken creates an API token with the given name. The returned Token
+// carries the secret, which cannot be retrieved again.
+func (c UserClient) NewToken(name string) (Token, ClientError) {
+	t := tokenCreateResponse{}
+	err := c.client.NewRequest("POST", tokensEndpoint, TokenParams{Name: name}, &t, nil)
+	t.Token.Secret = t.BearerToken
+	return t.Token, err
+}
+
+// TokenParams contains all fields for token create
+type TokenParams struct {
+	Name string `json:"name"`
+}
+
+type tokenCreateResponse struct {
+	Token       Token  `json:"token"`
+	BearerToken string `json:"bearerToken"`
+}
+
+// RevokeToken deletes the API token by its ID
+func (c UserClient) RevokeToken(tokenID string) ClientError {
+	return c.client.NewRequest("DELETE", fmt.Sprintf("%s/%s", tokensEndpoint, tokenID), nil, nil, nil)
+}
@@ -0,0 +1,101 @@
+package now
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+)
+
+// WebhookSignatureHeader is the header carrying the signature of a delivery
+const WebhookSignatureHeader = "X-Zeit-Signature"
+
+const maxWebhookBodySize = 1 << 20
+
+// WebhookHandlerFunc handles a verified webhook event. Returning an error
+// responds with a server error so the delivery is retried.
+type WebhookHandlerFunc func(e WebhookEvent) error
+
+// WebhookHandler is an http.Handler verifying the signature of webhook
+// deliveries and dispatching the decoded events to registered handlers
+type WebhookHandler struct {
+	secret string
+
+	mu       sync.RWMutex
+	handlers map[WebhookEventType][]WebhookHandlerFunc
+	any      []WebhookHandlerFunc
+}
+
+// NewWebhookHandler returns a WebhookHandler verifying deliveries with the
+// webhook's secret
+func NewWebhookHandler(secret string) *WebhookHandler {
+	return &WebhookHandler{
+		secret:   secret,
+		handlers: make(map[WebhookEventType][]WebhookHandlerFunc),
+	}
+}
+
+// Handle registers fn for events of the given type
+func (h *WebhookHandler) Handle(t WebhookEventType, fn WebhookHandlerFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.handlers[t] = append(h.handlers[t], fn)
+}
+
+// HandleAll registers fn for every event
+func (h *WebhookHandler) HandleAll(fn WebhookHandlerFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.any = append(h.any, fn)
+}
+
+// ServeHTTP implements the http.Handler interface
+func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxWebhookBodySize))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+	if !VerifyWebhookSignature(h.secret, body, r.Header.Get(WebhookSignatureHeader)) {
+		http.Error(w, "invalid signature", http.StatusForbidden)
+		return
+	}
+
+	e := WebhookEvent{}
+	if err := json.Unmarshal(body, &e); err != nil {
+		http.Error(w, "invalid event", http.StatusBadRequest)
+		return
+	}
+
+	h.mu.RLock()
+	handlers := append(append([]WebhookHandlerFunc{}, h.handlers[e.Type]...), h.any...)
+	h.mu.RUnlock()
+	for _, fn := range handlers {
+		if err := fn(e); err != nil {
+			http.Error(w, "failed to handle event", http.StatusInternalServerError)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// VerifyWebhookSignature returns whether signature is the hex encoded
+// HMAC-SHA1 of body keyed with secret
+func VerifyWebhookSignature(secret string, body []byte, signature string) bool {
+	expected, err := hex.DecodeString(signature)
+	if err != nil || secret == "" {
+		return false
+	}
+	mac := hmac.New(sha1.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
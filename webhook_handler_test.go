@@ -0,0 +1,71 @@
+package now
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testWebhookSecret = "whsec"
+
+func sign(body string) string {
+	mac := hmac.New(sha1.New, []byte(testWebhookSecret))
+	mac.Write([]byte(body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestWebhookHandler(t *testing.T) {
+	body := `{"id":"evt_1","type":"deployment-ready","createdAt":1463160591406,"payload":{"deploymentId":"dpl_1"}}`
+	tests := []struct {
+		name      string
+		body      string
+		signature string
+		fail      bool
+		status    int
+		handled   bool
+	}{
+		{name: "valid signature", body: body, signature: sign(body), status: http.StatusOK, handled: true},
+		{name: "tampered body", body: strings.Replace(body, "dpl_1", "dpl_2", 1), signature: sign(body), status: http.StatusForbidden},
+		{name: "missing header", body: body, status: http.StatusForbidden},
+		{name: "handler error", body: body, signature: sign(body), fail: true, status: http.StatusInternalServerError, handled: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *WebhookEvent
+			h := NewWebhookHandler(testWebhookSecret)
+			h.Handle(WebhookDeploymentReady, func(e WebhookEvent) error {
+				got = &e
+				if tt.fail {
+					return errors.New("database password leaked")
+				}
+				return nil
+			})
+
+			req := httptest.NewRequest("POST", "/hook", strings.NewReader(tt.body))
+			if tt.signature != "" {
+				req.Header.Set(WebhookSignatureHeader, tt.signature)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Errorf("status %d, want %d", rec.Code, tt.status)
+			}
+			if handled := got != nil; handled != tt.handled {
+				t.Fatalf("handled %v, want %v", handled, tt.handled)
+			}
+			if got != nil && (got.ID != "evt_1" || got.Deployment == nil || got.Deployment.UID != "dpl_1") {
+				t.Errorf("unexpected event %+v", got)
+			}
+			if strings.Contains(rec.Body.String(), "password") {
+				t.Errorf("handler error leaked in response %q", rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package now
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebhookEventType represents a WebhookEvent type string
+type WebhookEventType string
+
+// WebhookEventTypes
+const (
+	WebhookDeploymentCreated WebhookEventType = "deployment"
+	WebhookDeploymentReady   WebhookEventType = "deployment-ready"
+	WebhookDeploymentError   WebhookEventType = "deployment-error"
+	WebhookDeploymentRemoved WebhookEventType = "deployment-removed"
+)
+
+// Webhook is the contents of a webhook object. The secret used to sign
+// deliveries is only returned on creation.
+type Webhook struct {
+	ID      string             `json:"id"`
+	Name    string             `json:"name,omitempty"`
+	URL     string             `json:"url"`
+	Events  []WebhookEventType `json:"events"`
+	Secret  string             `json:"secret,omitempty"`
+	Created *time.Time         `json:"created,omitempty"`
+}
+
+// WebhookEvent is a delivery received by a webhook. Deployment is set for
+// deployment events; Payload keeps the raw payload for other fields.
+type WebhookEvent struct {
+	ID         string
+	Type       WebhookEventType
+	Created    time.Time
+	TeamID     string
+	UserID     string
+	Deployment *Deployment
+	Payload    json.RawMessage
+}
+
+// UnmarshalJSON implements json.Unmarshaler, decoding the deployment from
+// the payload
+func (e *WebhookEvent) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		ID        string           `json:"id"`
+		Type      WebhookEventType `json:"type"`
+		CreatedAt int64            `json:"createdAt"`
+		TeamID    string           `json:"teamId"`
+		UserID    string           `json:"userId"`
+		Payload   json.RawMessage  `json:"payload"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	e.ID = raw.ID
+	e.Type = raw.Type
+	e.Created = time.Unix(0, raw.CreatedAt*int64(time.Millisecond))
+	e.TeamID = raw.TeamID
+	e.UserID = raw.UserID
+	e.Payload = raw.Payload
+	e.Deployment = nil
+
+	if len(raw.Payload) == 0 {
+		return nil
+	}
+	var payload struct {
+		DeploymentID string      `json:"deploymentId"`
+		Deployment   *Deployment `json:"deployment"`
+	}
+	if err := json.Unmarshal(raw.Payload, &payload); err != nil {
+		return err
+	}
+	if payload.Deployment == nil && payload.DeploymentID != "" {
+		payload.Deployment = &Deployment{}
+	}
+	if d := payload.Deployment; d != nil && d.UID == "" {
+		d.UID = payload.DeploymentID
+	}
+	e.Deployment = payload.Deployment
+	return nil
+}
@@ -0,0 +1,48 @@
+package now
+
+import (
+	"fmt"
+)
+
+const webhooksEndpoint = "/v1/integrations/webhooks"
+
+// WebhooksClient contains the methods for the Webhook API
+type WebhooksClient struct {
+	client *Client
+}
+
+// New creates a webhook delivering the given events to url. The returned
+// Webhook carries the secret needed to verify deliveries.
+func (c WebhooksClient) New(name, url string, events []WebhookEventType) (Webhook, ClientError) {
+	return c.NewFromParams(WebhookParams{
+		Name:   name,
+		URL:    url,
+		Events: events,
+	})
+}
+
+// NewFromParams creates a webhook from params
+func (c WebhooksClient) NewFromParams(params WebhookParams) (Webhook, ClientError) {
+	w := Webhook{}
+	err := c.client.NewRequest("POST", webhooksEndpoint, params, &w, nil)
+	return w, err
+}
+
+// WebhookParams contains all fields for webhook create
+type WebhookParams struct {
+	Name   string             `json:"name"`
+	URL    string             `json:"url"`
+	Events []WebhookEventType `json:"events"`
+}
+
+// List retrieves a list of all the webhooks under the account
+func (c WebhooksClient) List() ([]Webhook, ClientError) {
+	var w []Webhook
+	err := c.client.NewRequest("GET", webhooksEndpoint, nil, &w, nil)
+	return w, err
+}
+
+// Delete deletes the webhook by its ID
+func (c WebhooksClient) Delete(webhookID string) ClientError {
+	return c.client.NewRequest("DELETE", fmt.Sprintf("%s/%s", webhooksEndpoint, webhookID), nil, nil, nil)
+}